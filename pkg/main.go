@@ -2,6 +2,8 @@ package main
 
 import (
 	"os"
+	// embeds the time zone database for the time zones of the queries and index patterns
+	_ "time/tzdata"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
@@ -27,27 +27,21 @@ var (
 	clientLog = log.New()
 )
 
-var newDatasourceHttpClient = func(ds *backend.DataSourceInstanceSettings) (*http.Client, error) {
-	jsonData := map[string]interface{}{}
-	err := json.Unmarshal(ds.JSONData, &jsonData)
-	if err != nil {
-		return nil, fmt.Errorf("error reading settings: %w", err)
-	}
-
-	httpClientProvider := httpclient.NewProvider()
-	httpClientOptions, err := ds.HTTPClientOptions()
-	if err != nil {
-		return nil, fmt.Errorf("failed to create HTTP client options: %w", err)
-	}
+// defaultMaxConcurrentQueries is the number of requests a query sends concurrently when the
+// datasource doesn't configure `maxConcurrentQueries`
+const defaultMaxConcurrentQueries = 5
 
+// newDatasourceHttpClient creates the HTTP client of a datasource instance, which signs the requests for
+// the aoss service of serverless collections instead of es when SigV4 is enabled
+var newDatasourceHttpClient = func(httpClientOptions httpclient.Options, serverless bool) (*http.Client, error) {
 	if httpClientOptions.SigV4 != nil {
 		httpClientOptions.SigV4.Service = "es"
-		if isServerless, ok := jsonData["serverless"].(bool); ok && isServerless {
+		if serverless {
 			httpClientOptions.SigV4.Service = "aoss"
 		}
 	}
 
-	httpClient, err := httpClientProvider.New(httpClientOptions)
+	httpClient, err := httpclient.NewProvider().New(httpClientOptions)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create HTTP client: %w", err)
 	}
@@ -66,6 +60,12 @@ type Client interface {
 	MultiSearch() *MultiSearchRequestBuilder
 	ExecutePPLQuery(r *PPLRequest) (*PPLResponse, error)
 	PPL() *PPLRequestBuilder
+	ExecuteSQLQuery(r *SQLRequest) (*PPLResponse, error)
+	SQL() *SQLRequestBuilder
+	FetchNextPage(language QueryLanguage, cursor string) (*PPLResponse, error)
+	CloseCursor(language QueryLanguage, cursor string) error
+	GetClusterInfo() (*ClusterInfo, error)
+	GetMapping() (map[string]interface{}, error)
 	EnableDebug()
 }
 
@@ -79,10 +79,26 @@ func extractVersion(v *simplejson.Json) (*semver.Version, error) {
 	return semver.NewVersion(versionString)
 }
 
-// NewClient creates a new OpenSearch client
-var NewClient = func(ctx context.Context, ds *backend.DataSourceInstanceSettings, timeRange *backend.TimeRange) (Client, error) {
-	jsonDataStr := ds.JSONData
-	jsonData, err := simplejson.NewJson([]byte(jsonDataStr))
+// DatasourceInfo holds the parsed settings and the HTTP client of a datasource instance, which are shared
+// by all the clients created for the requests of the instance
+type DatasourceInfo struct {
+	Settings             *backend.DataSourceInstanceSettings
+	JSONData             *simplejson.Json
+	HTTPClient           *http.Client
+	Headers              map[string]string
+	Version              *semver.Version
+	Flavor               Flavor
+	TimeField            string
+	Database             string
+	MaxConcurrentQueries int
+	indexPattern         indexPattern
+	// requestSlots limits the requests in flight of all the clients of the instance to MaxConcurrentQueries
+	requestSlots chan struct{}
+}
+
+// NewDatasourceInfo parses the settings of a datasource instance and creates its HTTP client
+func NewDatasourceInfo(ds *backend.DataSourceInstanceSettings) (*DatasourceInfo, error) {
+	jsonData, err := simplejson.NewJson(ds.JSONData)
 	if err != nil {
 		return nil, err
 	}
@@ -106,29 +122,70 @@ var NewClient = func(ctx context.Context, ds *backend.DataSourceInstanceSettings
 	}
 
 	indexInterval := jsonData.Get("interval").MustString()
-	ip, err := newIndexPattern(indexInterval, db)
+	indexLocation, err := time.LoadLocation(jsonData.Get("indexTimeZone").MustString("UTC"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid index time zone: %w", err)
+	}
+	ip, err := newIndexPattern(indexInterval, db, indexLocation)
+	if err != nil {
+		return nil, err
+	}
+
+	maxConcurrentQueries, err := jsonData.Get("maxConcurrentQueries").Int()
+	if err != nil {
+		// the config editor saves the value as a string
+		maxConcurrentQueries, _ = strconv.Atoi(jsonData.Get("maxConcurrentQueries").MustString())
+	}
+	if maxConcurrentQueries <= 0 {
+		maxConcurrentQueries = defaultMaxConcurrentQueries
+	}
+
+	httpClientOptions, err := ds.HTTPClientOptions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client options: %w", err)
+	}
+
+	httpClient, err := newDatasourceHttpClient(httpClientOptions, jsonData.Get("serverless").MustBool(false))
 	if err != nil {
 		return nil, err
 	}
 
-	indices, err := ip.GetIndices(timeRange)
+	return &DatasourceInfo{
+		Settings:             ds,
+		JSONData:             jsonData,
+		HTTPClient:           httpClient,
+		Headers:              httpClientOptions.Headers,
+		Version:              version,
+		Flavor:               Flavor(flavor),
+		TimeField:            timeField,
+		Database:             db,
+		MaxConcurrentQueries: maxConcurrentQueries,
+		indexPattern:         ip,
+		requestSlots:         make(chan struct{}, maxConcurrentQueries),
+	}, nil
+}
+
+// NewClient creates a new OpenSearch client for the time range of a request. The clients of a datasource
+// send at most MaxConcurrentQueries requests at the same time and stop waiting for a free slot once ctx is done.
+var NewClient = func(ctx context.Context, ds *DatasourceInfo, timeRange *backend.TimeRange) (Client, error) {
+	indices, err := ds.indexPattern.GetIndices(timeRange)
 	if err != nil {
 		return nil, err
 	}
 
-	index, err := ip.GetPPLIndex()
+	index, err := ds.indexPattern.GetPPLIndex()
 	if err != nil {
 		return nil, err
 	}
 
-	clientLog.Info("Creating new client", "version", version.String(), "timeField", timeField, "indices", strings.Join(indices, ", "), "PPL index", index)
+	clientLog.Info("Creating new client", "version", ds.Version.String(), "timeField", ds.TimeField, "indices", strings.Join(indices, ", "), "PPL index", index)
 
 	return &baseClientImpl{
 		ctx:       ctx,
 		ds:        ds,
-		version:   version,
-		flavor:    Flavor(flavor),
-		timeField: timeField,
+		version:   ds.Version,
+		flavor:    ds.Flavor,
+		timeField: ds.TimeField,
 		indices:   indices,
 		index:     index,
 		timeRange: timeRange,
@@ -137,7 +194,7 @@ var NewClient = func(ctx context.Context, ds *backend.DataSourceInstanceSettings
 
 type baseClientImpl struct {
 	ctx          context.Context
-	ds           *backend.DataSourceInstanceSettings
+	ds           *DatasourceInfo
 	flavor       Flavor
 	version      *semver.Version
 	timeField    string
@@ -147,6 +204,21 @@ type baseClientImpl struct {
 	debugEnabled bool
 }
 
+// acquireRequestSlot waits until the clients of the datasource have less than MaxConcurrentQueries requests
+// in flight, the returned func releases the slot
+func (c *baseClientImpl) acquireRequestSlot() (func(), error) {
+	if err := c.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	select {
+	case c.ds.requestSlots <- struct{}{}:
+		return func() { <-c.ds.requestSlots }, nil
+	case <-c.ctx.Done():
+		return nil, c.ctx.Err()
+	}
+}
+
 func (c *baseClientImpl) GetFlavor() Flavor {
 	return c.flavor
 }
@@ -166,12 +238,11 @@ func (c *baseClientImpl) GetIndex() string {
 func (c *baseClientImpl) GetMinInterval(queryInterval string) (time.Duration, error) {
 	intervalJSON := simplejson.New()
 	intervalJSON.Set("interval", queryInterval)
-	return tsdb.GetIntervalFrom(c.ds, intervalJSON, 5*time.Second)
+	return tsdb.GetIntervalFrom(c.ds.Settings, intervalJSON, 5*time.Second)
 }
 
 func (c *baseClientImpl) getSettings() *simplejson.Json {
-	settings, _ := simplejson.NewJson(c.ds.JSONData)
-	return settings
+	return c.ds.JSONData
 }
 
 type multiRequest struct {
@@ -219,7 +290,7 @@ func (c *baseClientImpl) encodeBatchRequests(requests []*multiRequest) ([]byte,
 }
 
 func (c *baseClientImpl) executeRequest(method, uriPath, uriQuery string, body []byte) (*response, error) {
-	u, err := url.Parse(c.ds.URL)
+	u, err := url.Parse(c.ds.Settings.URL)
 	if err != nil {
 		return nil, err
 	}
@@ -250,44 +321,36 @@ func (c *baseClientImpl) executeRequest(method, uriPath, uriQuery string, body [
 	req.Header.Set("User-Agent", "Grafana")
 	req.Header.Set("Content-Type", "application/json")
 
-	dsHttpOpts, err := c.ds.HTTPClientOptions()
-	if err != nil {
-		return nil, err
-	}
-	for k, v := range dsHttpOpts.Headers {
+	for k, v := range c.ds.Headers {
 		req.Header.Set(k, v)
 	}
 
-	secureJsonData := c.ds.DecryptedSecureJSONData
+	settings := c.ds.Settings
+	secureJsonData := settings.DecryptedSecureJSONData
 
-	if c.ds.BasicAuthEnabled {
+	if settings.BasicAuthEnabled {
 		clientLog.Debug("Request configured to use basic authentication")
 		basicAuthPassword := secureJsonData["basicAuthPassword"]
-		req.SetBasicAuth(c.ds.BasicAuthUser, basicAuthPassword)
+		req.SetBasicAuth(settings.BasicAuthUser, basicAuthPassword)
 	}
 
-	if !c.ds.BasicAuthEnabled && c.ds.User != "" {
+	if !settings.BasicAuthEnabled && settings.User != "" {
 		clientLog.Debug("Request configured to use basic authentication")
 		password := secureJsonData["password"]
-		req.SetBasicAuth(c.ds.User, password)
+		req.SetBasicAuth(settings.User, password)
 	}
 
-	if req.Method != http.MethodGet && c.getSettings().Get("serverless").MustBool(false) {
+	if req.Method != http.MethodGet && c.isServerless() {
 		req.Header.Set("x-amz-content-sha256", fmt.Sprintf("%x", sha256.Sum256(body)))
 	}
 
-	httpClient, err := newDatasourceHttpClient(c.ds)
-	if err != nil {
-		return nil, err
-	}
-
 	start := time.Now()
 	defer func() {
 		elapsed := time.Since(start)
 		clientLog.Debug("Executed request", "took", elapsed)
 	}()
 	//nolint:bodyclose
-	resp, err := ctxhttp.Do(c.ctx, httpClient, req)
+	resp, err := ctxhttp.Do(c.ctx, c.ds.HTTPClient, req)
 	if err != nil {
 		return nil, err
 	}
@@ -300,6 +363,12 @@ func (c *baseClientImpl) executeRequest(method, uriPath, uriQuery string, body [
 func (c *baseClientImpl) ExecuteMultisearch(r *MultiSearchRequest) (*MultiSearchResponse, error) {
 	clientLog.Debug("Executing multisearch", "search requests", len(r.Requests))
 
+	release, err := c.acquireRequestSlot()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	multiRequests := c.createMultiSearchRequests(r.Requests)
 	queryParams := c.getMultiSearchQueryParameters()
 	clientRes, err := c.executeBatchRequest("_msearch", queryParams, multiRequests)
@@ -407,6 +476,57 @@ func (c *baseClientImpl) getMultiSearchQueryParameters() string {
 	return ""
 }
 
+// GetClusterInfo returns the flavor and version reported by the root endpoint of the cluster
+func (c *baseClientImpl) GetClusterInfo() (*ClusterInfo, error) {
+	var info ClusterInfo
+	if err := c.executeGetRequest("/", "", &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// GetMapping returns the mappings of the indices that match the index pattern in the time range of the client
+func (c *baseClientImpl) GetMapping() (map[string]interface{}, error) {
+	mapping := make(map[string]interface{})
+	uriPath := path.Join(strings.Join(c.indices, ","), "_mapping")
+	if err := c.executeGetRequest(uriPath, "ignore_unavailable=true&allow_no_indices=true", &mapping); err != nil {
+		return nil, err
+	}
+	return mapping, nil
+}
+
+func (c *baseClientImpl) executeGetRequest(uriPath, uriQuery string, v interface{}) error {
+	release, err := c.acquireRequestSlot()
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	clientRes, err := c.executeRequest(http.MethodGet, uriPath, uriQuery, nil)
+	if err != nil {
+		return err
+	}
+	res := clientRes.httpResponse
+	defer res.Body.Close()
+
+	clientLog.Debug("Received response", "path", uriPath, "code", res.StatusCode, "status", res.Status)
+
+	if res.StatusCode >= http.StatusBadRequest {
+		body, _ := io.ReadAll(res.Body)
+		if bodyJSON, err := simplejson.NewJson(body); err == nil {
+			if reason := bodyJSON.GetPath("error", "reason").MustString(); reason != "" {
+				return fmt.Errorf("request to %s failed with status %s: %s", uriPath, res.Status, reason)
+			}
+		}
+		return fmt.Errorf("request to %s failed with status %s", uriPath, res.Status)
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+		return fmt.Errorf("error while decoding response of %s: %w", uriPath, err)
+	}
+	return nil
+}
+
 func (c *baseClientImpl) MultiSearch() *MultiSearchRequestBuilder {
 	return NewMultiSearchRequestBuilder(c.GetFlavor(), c.GetVersion())
 }
@@ -448,7 +568,7 @@ func (c *baseClientImpl) encodePPLRequests(requests *pplRequest) ([]byte, error)
 }
 
 func (c *baseClientImpl) executePPLQueryRequest(method, uriPath string, body []byte) (*pplresponse, error) {
-	u, err := url.Parse(c.ds.URL)
+	u, err := url.Parse(c.ds.Settings.URL)
 	if err != nil {
 		return nil, err
 	}
@@ -477,31 +597,28 @@ func (c *baseClientImpl) executePPLQueryRequest(method, uriPath string, body []b
 
 	req.Header.Set("User-Agent", "Grafana")
 	req.Header.Set("Content-Type", "application/json")
-	dsHttpOpts, err := c.ds.HTTPClientOptions()
-	if err != nil {
-		return nil, err
-	}
-	for k, v := range dsHttpOpts.Headers {
+	for k, v := range c.ds.Headers {
 		req.Header.Set(k, v)
 	}
 
-	secureJsonData := c.ds.DecryptedSecureJSONData
+	settings := c.ds.Settings
+	secureJsonData := settings.DecryptedSecureJSONData
 
-	if c.ds.BasicAuthEnabled {
+	if settings.BasicAuthEnabled {
 		clientLog.Debug("Request configured to use basic authentication")
 		basicAuthPassword := secureJsonData["basicAuthPassword"]
-		req.SetBasicAuth(c.ds.BasicAuthUser, basicAuthPassword)
+		req.SetBasicAuth(settings.BasicAuthUser, basicAuthPassword)
 	}
 
-	if !c.ds.BasicAuthEnabled && c.ds.User != "" {
+	if !settings.BasicAuthEnabled && settings.User != "" {
 		clientLog.Debug("Request configured to use basic authentication")
 		password := secureJsonData["password"]
-		req.SetBasicAuth(c.ds.User, password)
+		req.SetBasicAuth(settings.User, password)
 	}
 
-	httpClient, err := newDatasourceHttpClient(c.ds)
-	if err != nil {
-		return nil, err
+	// serverless collections require the hash of the payload
+	if c.isServerless() {
+		req.Header.Set("x-amz-content-sha256", fmt.Sprintf("%x", sha256.Sum256(body)))
 	}
 
 	start := time.Now()
@@ -510,7 +627,7 @@ func (c *baseClientImpl) executePPLQueryRequest(method, uriPath string, body []b
 		clientLog.Debug("Executed request", "took", elapsed)
 	}()
 	//nolint:bodyclose
-	resp, err := ctxhttp.Do(c.ctx, httpClient, req)
+	resp, err := ctxhttp.Do(c.ctx, c.ds.HTTPClient, req)
 	if err != nil {
 		return nil, err
 	}
@@ -520,39 +637,136 @@ func (c *baseClientImpl) executePPLQueryRequest(method, uriPath string, body []b
 	}, nil
 }
 
+// The PPL and SQL endpoints moved from the Open Distro paths to the plugins paths in OpenSearch 1.0,
+// OpenSearch 3.0 removes the Open Distro paths
+const (
+	pplPluginsPath    = "_plugins/_ppl"
+	pplOpendistroPath = "_opendistro/_ppl"
+	sqlPluginsPath    = "_plugins/_sql"
+	sqlOpendistroPath = "_opendistro/_sql"
+)
+
 func (c *baseClientImpl) ExecutePPLQuery(r *PPLRequest) (*PPLResponse, error) {
 	clientLog.Debug("Executing PPL")
+	return c.executePPLOrSQLQuery(LanguagePPL, c.getEndpointPaths(LanguagePPL), createPPLRequest(r))
+}
+
+// ExecuteSQLQuery executes a SQL query, the SQL endpoint responds in the same format as the PPL endpoint
+func (c *baseClientImpl) ExecuteSQLQuery(r *SQLRequest) (*PPLResponse, error) {
+	clientLog.Debug("Executing SQL")
+	return c.executePPLOrSQLQuery(LanguageSQL, c.getEndpointPaths(LanguageSQL), &pplRequest{body: r})
+}
+
+// FetchNextPage fetches the page of a PPL or SQL response following the page with the cursor. The
+// pages after the first one have no schema.
+func (c *baseClientImpl) FetchNextPage(language QueryLanguage, cursor string) (*PPLResponse, error) {
+	clientLog.Debug("Fetching the next page", "language", language)
+	return c.executePPLOrSQLQuery(language, c.getEndpointPaths(language), &pplRequest{body: &CursorRequest{Cursor: cursor}})
+}
+
+// CloseCursor releases the resources of a cursor whose remaining pages are not fetched, the cluster
+// otherwise keeps them until the cursor expires
+func (c *baseClientImpl) CloseCursor(language QueryLanguage, cursor string) error {
+	clientLog.Debug("Closing the cursor", "language", language)
+	paths := c.getEndpointPaths(language)
+	for i := range paths {
+		paths[i] += "/close"
+	}
+
+	res, err := c.executePPLOrSQLQuery(language, paths, &pplRequest{body: &CursorRequest{Cursor: cursor}})
+	if err != nil {
+		return err
+	}
+	if res.Status >= http.StatusBadRequest {
+		return fmt.Errorf("failed to close the cursor, the cluster responded with status %d", res.Status)
+	}
+	return nil
+}
+
+// getEndpointPaths returns the paths of the PPL or SQL endpoint in the order they are tried. Open Distro
+// for Elasticsearch only has the Open Distro paths, OpenSearch has the plugins paths since 1.0 and dropped
+// the Open Distro paths in 3.0, and serverless collections only have the plugins paths.
+func (c *baseClientImpl) getEndpointPaths(language QueryLanguage) []string {
+	pluginsPath, opendistroPath := pplPluginsPath, pplOpendistroPath
+	if language == LanguageSQL {
+		pluginsPath, opendistroPath = sqlPluginsPath, sqlOpendistroPath
+	}
+
+	switch {
+	case c.isServerless():
+		return []string{pluginsPath}
+	case c.flavor != OpenSearch:
+		return []string{opendistroPath}
+	case c.version.Major() >= 3:
+		return []string{pluginsPath}
+	case c.version.Major() >= 1:
+		return []string{pluginsPath, opendistroPath}
+	default:
+		return []string{opendistroPath, pluginsPath}
+	}
+}
+
+func (c *baseClientImpl) isServerless() bool {
+	return c.getSettings().Get("serverless").MustBool(false)
+}
+
+// executePPLOrSQLQuery sends the request to the first path of the endpoint, and to the next path when
+// the cluster doesn't have the endpoint
+func (c *baseClientImpl) executePPLOrSQLQuery(language QueryLanguage, uriPaths []string, req *pplRequest) (*PPLResponse, error) {
+	release, err := c.acquireRequestSlot()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	for i, uriPath := range uriPaths {
+		pr, err := c.executePPLOrSQLRequest(uriPath, req)
+		if err != nil {
+			return nil, err
+		}
+
+		// the plugin also responds with 404 to queries of missing indices, with the type of the error
+		if pr.Status != http.StatusNotFound || pr.Error["type"] != nil {
+			return pr, nil
+		}
+		if i < len(uriPaths)-1 {
+			clientLog.Debug("Endpoint not found, trying the next path", "path", uriPath, "next path", uriPaths[i+1])
+			continue
+		}
+		if c.isServerless() {
+			return nil, fmt.Errorf("%s is not supported by the serverless collection, %s returned 404 Not Found", language, uriPath)
+		}
+		return nil, fmt.Errorf("%s is not supported by the cluster, check that the SQL plugin is installed: %s returned 404 Not Found", language, uriPath)
+	}
+
+	return nil, fmt.Errorf("no endpoint to execute %s", language)
+}
 
-	req := createPPLRequest(r)
-	clientRes, err := c.executePPLRequest("_opendistro/_ppl", req)
+func (c *baseClientImpl) executePPLOrSQLRequest(uriPath string, req *pplRequest) (*PPLResponse, error) {
+	clientRes, err := c.executePPLRequest(uriPath, req)
 	if err != nil {
 		return nil, err
 	}
 	resp := clientRes.httpResponse
 	defer resp.Body.Close()
 
-	clientLog.Debug("Received PPL response", "code", resp.StatusCode, "status", resp.Status, "content-length", resp.ContentLength)
+	clientLog.Debug("Received PPL response", "path", uriPath, "code", resp.StatusCode, "status", resp.Status, "content-length", resp.ContentLength)
 
 	start := time.Now()
 	clientLog.Debug("Decoding PPL json response")
 
-	var bodyBytes []byte
-	if c.debugEnabled {
-		tmpBytes, err := io.ReadAll(resp.Body)
-		if err != nil {
-			clientLog.Error("failed to read http response bytes", "error", err)
-		} else {
-			bodyBytes = make([]byte, len(tmpBytes))
-			copy(bodyBytes, tmpBytes)
-			resp.Body = io.NopCloser(bytes.NewBuffer(tmpBytes))
-		}
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
 	}
 
 	var pr PPLResponse
-	dec := json.NewDecoder(resp.Body)
-	err = dec.Decode(&pr)
-	if err != nil {
-		return nil, err
+	if err := json.Unmarshal(bodyBytes, &pr); err != nil {
+		// a cluster without the endpoint doesn't necessarily respond with JSON
+		if resp.StatusCode != http.StatusNotFound {
+			return nil, err
+		}
+		pr = PPLResponse{}
 	}
 
 	elapsed := time.Since(start)
@@ -561,7 +775,7 @@ func (c *baseClientImpl) ExecutePPLQuery(r *PPLRequest) (*PPLResponse, error) {
 	pr.Status = resp.StatusCode
 
 	if c.debugEnabled {
-		bodyJSON, err := simplejson.NewFromReader(bytes.NewBuffer(bodyBytes))
+		bodyJSON, err := simplejson.NewJson(bodyBytes)
 		var data *simplejson.Json
 		if err != nil {
 			clientLog.Error("failed to decode http response into json", "error", err)
@@ -577,9 +791,6 @@ func (c *baseClientImpl) ExecutePPLQuery(r *PPLRequest) (*PPLResponse, error) {
 			},
 		}
 	}
-	if err != nil {
-		return nil, err
-	}
 	return &pr, nil
 }
 
@@ -592,3 +803,7 @@ func createPPLRequest(request *PPLRequest) *pplRequest {
 func (c *baseClientImpl) PPL() *PPLRequestBuilder {
 	return NewPPLRequestBuilder(c.GetIndex())
 }
+
+func (c *baseClientImpl) SQL() *SQLRequestBuilder {
+	return NewSQLRequestBuilder(c.GetIndex())
+}
@@ -15,11 +15,14 @@ import (
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/bitly/go-simplejson"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
 	"github.com/grafana/opensearch-datasource/pkg/tsdb"
 	"github.com/grafana/opensearch-datasource/pkg/utils"
 	"github.com/stretchr/testify/assert"
@@ -31,13 +34,13 @@ import (
 //nolint:goconst
 func TestClient(t *testing.T) {
 	Convey("Test opensearch client", t, func() {
-		Convey("NewClient", func() {
+		Convey("NewDatasourceInfo", func() {
 			Convey("When no version set should return error", func() {
 				ds := &backend.DataSourceInstanceSettings{
 					JSONData: utils.NewRawJsonFromAny(make(map[string]interface{})),
 				}
 
-				_, err := NewClient(context.Background(), ds, nil)
+				_, err := NewDatasourceInfo(ds)
 				So(err, ShouldNotBeNil)
 			})
 
@@ -48,7 +51,7 @@ func TestClient(t *testing.T) {
 					}),
 				}
 
-				_, err := NewClient(context.Background(), ds, nil)
+				_, err := NewDatasourceInfo(ds)
 				So(err, ShouldNotBeNil)
 			})
 
@@ -60,9 +63,24 @@ func TestClient(t *testing.T) {
 					}),
 				}
 
-				_, err := NewClient(context.Background(), ds, nil)
+				_, err := NewDatasourceInfo(ds)
 				So(err, ShouldNotBeNil)
 			})
+
+			Convey("When unknown index time zone set should return error", func() {
+				ds := &backend.DataSourceInstanceSettings{
+					JSONData: utils.NewRawJsonFromAny(map[string]interface{}{
+						"version":       "1.0.0",
+						"timeField":     "@timestamp",
+						"interval":      "Daily",
+						"indexTimeZone": "Europe/Nowhere",
+					}),
+				}
+
+				_, err := NewDatasourceInfo(ds)
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldStartWith, "invalid index time zone: ")
+			})
 		})
 
 		httpClientScenario(t, "Given a fake http client and a v1.0.0 client with response", &backend.DataSourceInstanceSettings{
@@ -152,6 +170,39 @@ func TestClient(t *testing.T) {
 		})
 	})
 
+	Convey("Test cluster info and mapping requests", t, func() {
+		httpClientScenario(t, "Given a fake http client", &backend.DataSourceInstanceSettings{
+			JSONData: utils.NewRawJsonFromAny(map[string]interface{}{
+				"version":   "1.0.0",
+				"timeField": "@timestamp",
+				"interval":  "Daily",
+				"database":  "[metrics-]YYYY.MM.DD",
+			}),
+		}, func(sc *scenarioContext) {
+			Convey("When getting the cluster info", func() {
+				sc.responseBody = `{ "version": { "distribution": "opensearch", "number": "2.5.0" } }`
+				info, err := sc.client.GetClusterInfo()
+				So(err, ShouldBeNil)
+
+				So(sc.request.Method, ShouldEqual, http.MethodGet)
+				So(sc.request.URL.Path, ShouldEqual, "/")
+				So(info.Version.Number, ShouldEqual, "2.5.0")
+				So(info.GetFlavor(), ShouldEqual, OpenSearch)
+			})
+
+			Convey("When getting the mapping", func() {
+				sc.responseBody = `{ "metrics-2018.05.15": { "mappings": { "properties": { "@timestamp": { "type": "date" } } } } }`
+				mapping, err := sc.client.GetMapping()
+				So(err, ShouldBeNil)
+
+				So(sc.request.Method, ShouldEqual, http.MethodGet)
+				So(sc.request.URL.Path, ShouldEqual, "/metrics-2018.05.15/_mapping")
+				So(sc.request.URL.RawQuery, ShouldEqual, "ignore_unavailable=true&allow_no_indices=true")
+				So(mapping, ShouldContainKey, "metrics-2018.05.15")
+			})
+		})
+	})
+
 	Convey("Test PPL opensearch client", t, func() {
 		httpClientScenario(t, "Given a fake http client and a v1.0.0 client with PPL response", &backend.DataSourceInstanceSettings{
 			JSONData: utils.NewRawJsonFromAny(map[string]interface{}{
@@ -179,7 +230,7 @@ func TestClient(t *testing.T) {
 				Convey("Should send correct request and payload", func() {
 					So(sc.request, ShouldNotBeNil)
 					So(sc.request.Method, ShouldEqual, http.MethodPost)
-					So(sc.request.URL.Path, ShouldEqual, "/_opendistro/_ppl")
+					So(sc.request.URL.Path, ShouldEqual, "/_plugins/_ppl")
 
 					So(sc.requestBody, ShouldNotBeNil)
 
@@ -198,6 +249,29 @@ func TestClient(t *testing.T) {
 					So(res.Status, ShouldEqual, 200)
 				})
 			})
+
+			Convey("When executing SQL", func() {
+				b := sc.client.SQL()
+				b.AddSQLQueryString(sc.client.GetTimeField(), "$timeTo", "$timeFrom", "SELECT count(*) FROM metrics-* GROUP BY host")
+				sql, err := b.Build()
+				So(err, ShouldBeNil)
+				res, err := sc.client.ExecuteSQLQuery(sql)
+				So(err, ShouldBeNil)
+
+				Convey("Should send correct request and payload", func() {
+					So(sc.request.Method, ShouldEqual, http.MethodPost)
+					So(sc.request.URL.Path, ShouldEqual, "/_plugins/_sql")
+
+					jBody, err := simplejson.NewJson(sc.requestBody.Bytes())
+					So(err, ShouldBeNil)
+					So(jBody.Get("query").MustString(), ShouldEqual, "SELECT count(*) FROM metrics-* WHERE `@timestamp` >= timestamp('$timeFrom') AND `@timestamp` <= timestamp('$timeTo') GROUP BY host")
+				})
+
+				Convey("Should parse response", func() {
+					So(res.Schema, ShouldHaveLength, 2)
+					So(res.Datarows, ShouldHaveLength, 3)
+				})
+			})
 		})
 	})
 }
@@ -257,14 +331,9 @@ func httpClientScenario(t *testing.T, desc string, ds *backend.DataSourceInstanc
 		to := time.Date(2018, 5, 15, 17, 55, 0, 0, time.UTC)
 		timeRange := &backend.TimeRange{From: from, To: to}
 
-		c, err := NewClient(context.Background(), ds, timeRange)
-		So(err, ShouldBeNil)
-		So(c, ShouldNotBeNil)
-		sc.client = c
-
 		currentNewDatasourceHttpClient := newDatasourceHttpClient
 
-		newDatasourceHttpClient = func(ds *backend.DataSourceInstanceSettings) (*http.Client, error) {
+		newDatasourceHttpClient = func(httpclient.Options, bool) (*http.Client, error) {
 			return ts.Client(), nil
 		}
 
@@ -273,6 +342,14 @@ func httpClientScenario(t *testing.T, desc string, ds *backend.DataSourceInstanc
 			newDatasourceHttpClient = currentNewDatasourceHttpClient
 		}()
 
+		dsInfo, err := NewDatasourceInfo(ds)
+		So(err, ShouldBeNil)
+
+		c, err := NewClient(context.Background(), dsInfo, timeRange)
+		So(err, ShouldBeNil)
+		So(c, ShouldNotBeNil)
+		sc.client = c
+
 		fn(sc)
 	})
 }
@@ -300,6 +377,295 @@ func Test_client_returns_error_with_invalid_json_response(t *testing.T) {
 	})
 }
 
+func Test_clients_of_a_datasource_share_the_http_client(t *testing.T) {
+	currentNewDatasourceHttpClient := newDatasourceHttpClient
+	t.Cleanup(func() {
+		newDatasourceHttpClient = currentNewDatasourceHttpClient
+	})
+
+	created := 0
+	httpClient := &http.Client{}
+	newDatasourceHttpClient = func(httpclient.Options, bool) (*http.Client, error) {
+		created++
+		return httpClient, nil
+	}
+
+	dsInfo, err := NewDatasourceInfo(&backend.DataSourceInstanceSettings{
+		JSONData: utils.NewRawJsonFromAny(map[string]interface{}{
+			"version":   "1.0.0",
+			"timeField": "@timestamp",
+			"interval":  "Daily",
+			"database":  "[metrics-]YYYY.MM.DD",
+		}),
+	})
+	require.NoError(t, err)
+
+	from := time.Date(2018, 5, 15, 17, 50, 0, 0, time.UTC)
+	for _, to := range []time.Time{from.Add(time.Hour), from.Add(48 * time.Hour)} {
+		c, err := NewClient(context.Background(), dsInfo, &backend.TimeRange{From: from, To: to})
+		require.NoError(t, err)
+		assert.Same(t, httpClient, c.(*baseClientImpl).ds.HTTPClient)
+	}
+	assert.Equal(t, 1, created)
+}
+
+func Test_http_client_is_created_with_the_parsed_settings(t *testing.T) {
+	currentNewDatasourceHttpClient := newDatasourceHttpClient
+	t.Cleanup(func() {
+		newDatasourceHttpClient = currentNewDatasourceHttpClient
+	})
+
+	var serverlessClient bool
+	var headers map[string]string
+	newDatasourceHttpClient = func(httpClientOptions httpclient.Options, serverless bool) (*http.Client, error) {
+		serverlessClient = serverless
+		headers = httpClientOptions.Headers
+		return &http.Client{}, nil
+	}
+
+	dsInfo, err := NewDatasourceInfo(&backend.DataSourceInstanceSettings{
+		JSONData: utils.NewRawJsonFromAny(map[string]interface{}{
+			"version":         "1.0.0",
+			"timeField":       "@timestamp",
+			"serverless":      true,
+			"httpHeaderName1": "X-Tenant",
+		}),
+		DecryptedSecureJSONData: map[string]string{"httpHeaderValue1": "team-a"},
+	})
+	require.NoError(t, err)
+
+	assert.True(t, serverlessClient)
+	assert.Equal(t, map[string]string{"X-Tenant": "team-a"}, headers)
+	assert.Equal(t, headers, dsInfo.Headers)
+}
+
+func Test_client_limits_the_concurrent_requests(t *testing.T) {
+	var inFlight, maxInFlight int32
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		_, _ = rw.Write([]byte(`{ "schema": [], "datarows": [] }`))
+	}))
+	defer ts.Close()
+
+	currentNewDatasourceHttpClient := newDatasourceHttpClient
+	t.Cleanup(func() {
+		newDatasourceHttpClient = currentNewDatasourceHttpClient
+	})
+	newDatasourceHttpClient = func(httpclient.Options, bool) (*http.Client, error) {
+		return ts.Client(), nil
+	}
+
+	dsInfo, err := NewDatasourceInfo(&backend.DataSourceInstanceSettings{
+		URL: ts.URL,
+		JSONData: utils.NewRawJsonFromAny(map[string]interface{}{
+			"version":              "1.0.0",
+			"timeField":            "@timestamp",
+			"database":             "metrics",
+			"maxConcurrentQueries": 2,
+		}),
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, dsInfo.MaxConcurrentQueries)
+
+	t.Run("Sends at most maxConcurrentQueries requests at the same time across the clients", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 6; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				c, err := NewClient(context.Background(), dsInfo, &backend.TimeRange{})
+				assert.NoError(t, err)
+				ppl, err := createPPLForTest(c)
+				assert.NoError(t, err)
+				_, err = c.ExecutePPLQuery(ppl)
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(2), atomic.LoadInt32(&maxInFlight))
+	})
+
+	t.Run("Doesn't send requests once the context is canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		c, err := NewClient(ctx, dsInfo, &backend.TimeRange{})
+		require.NoError(t, err)
+
+		ppl, err := createPPLForTest(c)
+		require.NoError(t, err)
+		_, err = c.ExecutePPLQuery(ppl)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func Test_PPL_endpoint_paths(t *testing.T) {
+	// the paths without a response are not found
+	newPPLTestClient := func(t *testing.T, jsonData map[string]interface{}, responses map[string]string) (Client, *[]string) {
+		t.Helper()
+		requestedPaths := make([]string, 0)
+		ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			requestedPaths = append(requestedPaths, r.URL.Path)
+			body, ok := responses[r.URL.Path]
+			if !ok {
+				rw.WriteHeader(http.StatusNotFound)
+				_, _ = rw.Write([]byte("Not Found"))
+				return
+			}
+			// error responses have the status in the body
+			var res struct{ Status int }
+			if err := jsonEncoding.Unmarshal([]byte(body), &res); err == nil && res.Status != 0 {
+				rw.WriteHeader(res.Status)
+			}
+			_, _ = rw.Write([]byte(body))
+		}))
+		t.Cleanup(ts.Close)
+
+		currentNewDatasourceHttpClient := newDatasourceHttpClient
+		t.Cleanup(func() {
+			newDatasourceHttpClient = currentNewDatasourceHttpClient
+		})
+		newDatasourceHttpClient = func(httpclient.Options, bool) (*http.Client, error) {
+			return ts.Client(), nil
+		}
+
+		jsonData["timeField"] = "@timestamp"
+		jsonData["database"] = "metrics"
+		dsInfo, err := NewDatasourceInfo(&backend.DataSourceInstanceSettings{
+			URL:      ts.URL,
+			JSONData: utils.NewRawJsonFromAny(jsonData),
+		})
+		require.NoError(t, err)
+		c, err := NewClient(context.Background(), dsInfo, &backend.TimeRange{})
+		require.NoError(t, err)
+		return c, &requestedPaths
+	}
+
+	executePPL := func(c Client) (*PPLResponse, error) {
+		ppl, err := createPPLForTest(c)
+		if err != nil {
+			return nil, err
+		}
+		return c.ExecutePPLQuery(ppl)
+	}
+
+	okResponse := `{ "schema": [{ "name": "count()", "type": "integer" }], "datarows": [[1]] }`
+
+	t.Run("Uses the plugins path on OpenSearch", func(t *testing.T) {
+		c, paths := newPPLTestClient(t, map[string]interface{}{"version": "2.5.0", "flavor": "opensearch"}, map[string]string{
+			"/_plugins/_ppl": okResponse,
+		})
+		res, err := executePPL(c)
+		require.NoError(t, err)
+		assert.Len(t, res.Datarows, 1)
+		assert.Equal(t, []string{"/_plugins/_ppl"}, *paths)
+	})
+
+	t.Run("Falls back to the Open Distro path when the plugins path is not found", func(t *testing.T) {
+		c, paths := newPPLTestClient(t, map[string]interface{}{"version": "1.0.0", "flavor": "opensearch"}, map[string]string{
+			"/_opendistro/_ppl": okResponse,
+		})
+		res, err := executePPL(c)
+		require.NoError(t, err)
+		assert.Len(t, res.Datarows, 1)
+		assert.Equal(t, []string{"/_plugins/_ppl", "/_opendistro/_ppl"}, *paths)
+	})
+
+	t.Run("Uses the Open Distro path on Elasticsearch", func(t *testing.T) {
+		c, paths := newPPLTestClient(t, map[string]interface{}{"version": "7.10.2", "flavor": "elasticsearch"}, map[string]string{
+			"/_opendistro/_ppl": okResponse,
+		})
+		_, err := executePPL(c)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"/_opendistro/_ppl"}, *paths)
+	})
+
+	t.Run("Chooses the paths from the flavor and the version", func(t *testing.T) {
+		tests := []struct {
+			flavor  string
+			version string
+			paths   []string
+		}{
+			{flavor: "opensearch", version: "0.9.0", paths: []string{"/_opendistro/_ppl", "/_plugins/_ppl"}},
+			{flavor: "opensearch", version: "1.3.0", paths: []string{"/_plugins/_ppl", "/_opendistro/_ppl"}},
+			{flavor: "opensearch", version: "2.11.1", paths: []string{"/_plugins/_ppl", "/_opendistro/_ppl"}},
+			{flavor: "opensearch", version: "3.0.0", paths: []string{"/_plugins/_ppl"}},
+			{flavor: "elasticsearch", version: "6.8.0", paths: []string{"/_opendistro/_ppl"}},
+			{flavor: "elasticsearch", version: "7.10.2", paths: []string{"/_opendistro/_ppl"}},
+		}
+		for _, test := range tests {
+			t.Run(test.flavor+" "+test.version, func(t *testing.T) {
+				c, paths := newPPLTestClient(t, map[string]interface{}{"version": test.version, "flavor": test.flavor}, map[string]string{})
+				_, err := executePPL(c)
+				assert.Error(t, err)
+				assert.Equal(t, test.paths, *paths)
+			})
+		}
+	})
+
+	t.Run("Doesn't fall back on errors of the query", func(t *testing.T) {
+		c, paths := newPPLTestClient(t, map[string]interface{}{"version": "2.5.0", "flavor": "opensearch"}, map[string]string{
+			"/_plugins/_ppl": `{ "error": { "reason": "no such index [metrics]", "type": "IndexNotFoundException" }, "status": 404 }`,
+		})
+
+		res, err := executePPL(c)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, res.Status)
+		assert.Equal(t, "no such index [metrics]", res.Error["reason"])
+		assert.Equal(t, []string{"/_plugins/_ppl"}, *paths)
+	})
+
+	t.Run("Returns an error when no path is found", func(t *testing.T) {
+		c, paths := newPPLTestClient(t, map[string]interface{}{"version": "2.5.0", "flavor": "opensearch"}, map[string]string{})
+		_, err := executePPL(c)
+		assert.EqualError(t, err, "PPL is not supported by the cluster, check that the SQL plugin is installed: _opendistro/_ppl returned 404 Not Found")
+		assert.Equal(t, []string{"/_plugins/_ppl", "/_opendistro/_ppl"}, *paths)
+	})
+
+	t.Run("Only uses the plugins path on serverless collections", func(t *testing.T) {
+		c, paths := newPPLTestClient(t, map[string]interface{}{"version": "2.5.0", "flavor": "opensearch", "serverless": true}, map[string]string{})
+		_, err := executePPL(c)
+		assert.EqualError(t, err, "PPL is not supported by the serverless collection, _plugins/_ppl returned 404 Not Found")
+		assert.Equal(t, []string{"/_plugins/_ppl"}, *paths)
+	})
+
+	t.Run("Fetches the next page of a response with a cursor", func(t *testing.T) {
+		c, paths := newPPLTestClient(t, map[string]interface{}{"version": "2.5.0", "flavor": "opensearch"}, map[string]string{
+			"/_plugins/_sql": `{ "datarows": [[2]], "total": 2, "size": 1, "cursor": "next" }`,
+		})
+		res, err := c.FetchNextPage(LanguageSQL, "first")
+		require.NoError(t, err)
+		assert.Equal(t, []Datarow{{float64(2)}}, res.Datarows)
+		assert.Equal(t, 2, res.Total)
+		assert.Equal(t, 1, res.Size)
+		assert.Equal(t, "next", res.Cursor)
+		assert.Equal(t, []string{"/_plugins/_sql"}, *paths)
+	})
+
+	t.Run("Closes a cursor", func(t *testing.T) {
+		c, paths := newPPLTestClient(t, map[string]interface{}{"version": "1.0.0", "flavor": "opensearch"}, map[string]string{
+			"/_opendistro/_sql/close": `{ "succeeded": true }`,
+		})
+		require.NoError(t, c.CloseCursor(LanguageSQL, "next"))
+		assert.Equal(t, []string{"/_plugins/_sql/close", "/_opendistro/_sql/close"}, *paths)
+	})
+
+	t.Run("Returns an error when the cursor is not closed", func(t *testing.T) {
+		c, _ := newPPLTestClient(t, map[string]interface{}{"version": "2.5.0", "flavor": "opensearch"}, map[string]string{
+			"/_plugins/_ppl/close": `{ "error": { "reason": "invalid cursor", "type": "IllegalArgumentException" }, "status": 400 }`,
+		})
+		assert.EqualError(t, c.CloseCursor(LanguagePPL, "next"), "failed to close the cursor, the cluster responded with status 400")
+	})
+}
+
 func Test_TLS_config_included_in_client_passed_from_decrypted_json_data(t *testing.T) {
 	// generates a Certificate Authority certificate and self-signed certificate for the server, similar to https://opensearch.org/docs/latest/security/configuration/generate-certificates/
 	ca, caPrivKey, caPEM, err := generateCaCertificate(t, "root.localhost")
@@ -325,14 +691,17 @@ func Test_TLS_config_included_in_client_passed_from_decrypted_json_data(t *testi
 	require.NoError(t, err)
 
 	// verify that newDatasourceHttpClient, when provided the client's JSON data from the config editor, is able to authenticate with the test server mutually
-	client, err := newDatasourceHttpClient(&backend.DataSourceInstanceSettings{
+	settings := &backend.DataSourceInstanceSettings{
 		JSONData: jsonEncoding.RawMessage(`{"tlsAuth":true, "tlsAuthWithCACert":true}`),
 		DecryptedSecureJSONData: map[string]string{
 			"tlsCACert":     caPEM.String(),
 			"tlsClientCert": clientCertPEM.String(),
 			"tlsClientKey":  clientKeyPEM.String(),
 		},
-	})
+	}
+	httpClientOptions, err := settings.HTTPClientOptions()
+	require.NoError(t, err)
+	client, err := newDatasourceHttpClient(httpClientOptions, false)
 	require.NoError(t, err)
 
 	resp, err := client.Get(server.URL)
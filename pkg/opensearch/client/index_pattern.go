@@ -23,12 +23,14 @@ type indexPattern interface {
 	GetPPLIndex() (string, error)
 }
 
-var newIndexPattern = func(interval string, pattern string) (indexPattern, error) {
+// newIndexPattern returns the index pattern of the datasource, the dates of the index names of a dynamic
+// index pattern are in location
+var newIndexPattern = func(interval string, pattern string, location *time.Location) (indexPattern, error) {
 	if interval == noInterval {
 		return &staticIndexPattern{indexName: pattern}, nil
 	}
 
-	return newDynamicIndexPattern(interval, pattern)
+	return newDynamicIndexPattern(interval, pattern, location)
 }
 
 type staticIndexPattern struct {
@@ -44,6 +46,7 @@ func (ip *staticIndexPattern) GetPPLIndex() (string, error) {
 	return ip.indexName, nil
 }
 
+// intervalGenerator generates the start of the intervals between from and to, in the location of from
 type intervalGenerator interface {
 	Generate(from, to time.Time) []time.Time
 }
@@ -51,10 +54,15 @@ type intervalGenerator interface {
 type dynamicIndexPattern struct {
 	interval          string
 	pattern           string
+	location          *time.Location
 	intervalGenerator intervalGenerator
 }
 
-func newDynamicIndexPattern(interval, pattern string) (*dynamicIndexPattern, error) {
+func newDynamicIndexPattern(interval, pattern string, location *time.Location) (*dynamicIndexPattern, error) {
+	if location == nil {
+		location = time.UTC
+	}
+
 	var generator intervalGenerator
 
 	switch strings.ToLower(interval) {
@@ -75,18 +83,24 @@ func newDynamicIndexPattern(interval, pattern string) (*dynamicIndexPattern, err
 	return &dynamicIndexPattern{
 		interval:          interval,
 		pattern:           pattern,
+		location:          location,
 		intervalGenerator: generator,
 	}, nil
 }
 
 func (ip *dynamicIndexPattern) GetIndices(timeRange *backend.TimeRange) ([]string, error) {
-	from := timeRange.From
-	to := timeRange.To
+	from := timeRange.From.In(ip.location)
+	to := timeRange.To.In(ip.location)
 	intervals := ip.intervalGenerator.Generate(from, to)
 	indices := make([]string, 0)
 
 	for _, t := range intervals {
-		indices = append(indices, formatDate(t, ip.pattern))
+		index := formatDate(t, ip.pattern)
+		// an hour is repeated when DST ends
+		if len(indices) > 0 && indices[len(indices)-1] == index {
+			continue
+		}
+		indices = append(indices, index)
 	}
 
 	return indices, nil
@@ -112,8 +126,8 @@ type hourlyInterval struct{}
 
 func (i *hourlyInterval) Generate(from, to time.Time) []time.Time {
 	intervals := []time.Time{}
-	start := time.Date(from.Year(), from.Month(), from.Day(), from.Hour(), 0, 0, 0, time.UTC)
-	end := time.Date(to.Year(), to.Month(), to.Day(), to.Hour(), 0, 0, 0, time.UTC)
+	start := time.Date(from.Year(), from.Month(), from.Day(), from.Hour(), 0, 0, 0, from.Location())
+	end := time.Date(to.Year(), to.Month(), to.Day(), to.Hour(), 0, 0, 0, from.Location())
 
 	intervals = append(intervals, start)
 
@@ -129,13 +143,13 @@ type dailyInterval struct{}
 
 func (i *dailyInterval) Generate(from, to time.Time) []time.Time {
 	intervals := []time.Time{}
-	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
-	end := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+	end := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, from.Location())
 
 	intervals = append(intervals, start)
 
 	for start.Before(end) {
-		start = start.Add(24 * time.Hour)
+		start = start.AddDate(0, 0, 1)
 		intervals = append(intervals, start)
 	}
 
@@ -146,22 +160,22 @@ type weeklyInterval struct{}
 
 func (i *weeklyInterval) Generate(from, to time.Time) []time.Time {
 	intervals := []time.Time{}
-	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
-	end := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+	end := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, from.Location())
 
 	for start.Weekday() != time.Monday {
-		start = start.Add(-24 * time.Hour)
+		start = start.AddDate(0, 0, -1)
 	}
 
 	for end.Weekday() != time.Monday {
-		end = end.Add(-24 * time.Hour)
+		end = end.AddDate(0, 0, -1)
 	}
 
 	year, week := start.ISOWeek()
 	intervals = append(intervals, start)
 
 	for start.Before(end) {
-		start = start.Add(24 * time.Hour)
+		start = start.AddDate(0, 0, 1)
 		nextYear, nextWeek := start.ISOWeek()
 		if nextYear != year || nextWeek != week {
 			intervals = append(intervals, start)
@@ -177,14 +191,14 @@ type monthlyInterval struct{}
 
 func (i *monthlyInterval) Generate(from, to time.Time) []time.Time {
 	intervals := []time.Time{}
-	start := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.UTC)
-	end := time.Date(to.Year(), to.Month(), 1, 0, 0, 0, 0, time.UTC)
+	start := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, from.Location())
+	end := time.Date(to.Year(), to.Month(), 1, 0, 0, 0, 0, from.Location())
 
 	month := start.Month()
 	intervals = append(intervals, start)
 
 	for start.Before(end) {
-		start = start.Add(24 * time.Hour)
+		start = start.AddDate(0, 0, 1)
 		nextMonth := start.Month()
 		if nextMonth != month {
 			intervals = append(intervals, start)
@@ -199,14 +213,14 @@ type yearlyInterval struct{}
 
 func (i *yearlyInterval) Generate(from, to time.Time) []time.Time {
 	intervals := []time.Time{}
-	start := time.Date(from.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
-	end := time.Date(to.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	start := time.Date(from.Year(), 1, 1, 0, 0, 0, 0, from.Location())
+	end := time.Date(to.Year(), 1, 1, 0, 0, 0, 0, from.Location())
 
 	year := start.Year()
 	intervals = append(intervals, start)
 
 	for start.Before(end) {
-		start = start.Add(24 * time.Hour)
+		start = start.AddDate(0, 0, 1)
 		nextYear := start.Year()
 		if nextYear != year {
 			intervals = append(intervals, start)
@@ -99,6 +99,43 @@ func TestIndexPattern(t *testing.T) {
 		})
 	})
 
+	Convey("Dynamic index patterns in a time zone", t, func() {
+		berlin, err := time.LoadLocation("Europe/Berlin")
+		So(err, ShouldBeNil)
+
+		getIndices := func(interval, pattern string, from, to time.Time) []string {
+			ip, err := newIndexPattern(interval, pattern, berlin)
+			So(err, ShouldBeNil)
+			indices, err := ip.GetIndices(&backend.TimeRange{From: from, To: to})
+			So(err, ShouldBeNil)
+			return indices
+		}
+
+		Convey("Should name the indices with the dates in the time zone", func() {
+			from := time.Date(2018, 5, 15, 22, 30, 0, 0, time.UTC)
+			to := time.Date(2018, 5, 15, 22, 40, 0, 0, time.UTC)
+			So(getIndices(intervalDaily, "[logs-]YYYY.MM.DD", from, to), ShouldResemble, []string{"logs-2018.05.16"})
+		})
+
+		Convey("Should return a day per index across the start of DST", func() {
+			from := time.Date(2018, 3, 24, 12, 0, 0, 0, time.UTC)
+			to := time.Date(2018, 3, 26, 12, 0, 0, 0, time.UTC)
+			So(getIndices(intervalDaily, "[logs-]YYYY.MM.DD", from, to), ShouldResemble, []string{"logs-2018.03.24", "logs-2018.03.25", "logs-2018.03.26"})
+		})
+
+		Convey("Should return the months across the end of DST", func() {
+			from := time.Date(2018, 9, 30, 22, 30, 0, 0, time.UTC)
+			to := time.Date(2018, 11, 1, 12, 0, 0, 0, time.UTC)
+			So(getIndices(intervalMonthly, "[logs-]YYYY.MM", from, to), ShouldResemble, []string{"logs-2018.10", "logs-2018.11"})
+		})
+
+		Convey("Should return the repeated hour at the end of DST once", func() {
+			from := time.Date(2018, 10, 28, 0, 30, 0, 0, time.UTC)
+			to := time.Date(2018, 10, 28, 1, 30, 0, 0, time.UTC)
+			So(getIndices(intervalHourly, "[logs-]YYYY.MM.DD.HH", from, to), ShouldResemble, []string{"logs-2018.10.28.02"})
+		})
+	})
+
 	Convey("Hourly interval", t, func() {
 		Convey("Should return 1 interval", func() {
 			from := time.Date(2018, 1, 1, 23, 1, 1, 0, time.UTC)
@@ -316,7 +353,7 @@ func TestIndexPattern(t *testing.T) {
 
 func indexPatternScenario(interval string, pattern string, timeRange *backend.TimeRange, fn func(indices []string)) {
 	Convey(fmt.Sprintf("Index pattern (interval=%s, index=%s", interval, pattern), func() {
-		ip, err := newIndexPattern(interval, pattern)
+		ip, err := newIndexPattern(interval, pattern, time.UTC)
 		So(err, ShouldBeNil)
 		So(ip, ShouldNotBeNil)
 		indices, err := ip.GetIndices(timeRange)
@@ -327,7 +364,7 @@ func indexPatternScenario(interval string, pattern string, timeRange *backend.Ti
 
 func pplIndexScenario(interval string, pattern string, fn func(index string)) {
 	Convey(fmt.Sprintf("Index pattern (interval=%s, index=%s", interval, pattern), func() {
-		ip, err := newIndexPattern(interval, pattern)
+		ip, err := newIndexPattern(interval, pattern, time.UTC)
 		So(err, ShouldBeNil)
 		So(ip, ShouldNotBeNil)
 		index, err := ip.GetPPLIndex()
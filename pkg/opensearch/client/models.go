@@ -16,6 +16,25 @@ const (
 	OpenSearch    Flavor = "opensearch"
 )
 
+// ClusterInfo represents the response of the root endpoint of the cluster
+type ClusterInfo struct {
+	Version ClusterVersion `json:"version"`
+}
+
+// ClusterVersion represents the version of the cluster. Distribution is only reported by OpenSearch.
+type ClusterVersion struct {
+	Number       string `json:"number"`
+	Distribution string `json:"distribution"`
+}
+
+// GetFlavor returns the flavor of the cluster based on the reported distribution
+func (i *ClusterInfo) GetFlavor() Flavor {
+	if i.Version.Distribution == string(OpenSearch) {
+		return OpenSearch
+	}
+	return Elasticsearch
+}
+
 type response struct {
 	httpResponse *http.Response
 	reqInfo      *SearchRequestInfo
@@ -77,6 +96,7 @@ type SearchResponseHits struct {
 
 // SearchResponse represents a search response
 type SearchResponse struct {
+	Status       int                    `json:"status"`
 	Error        map[string]interface{} `json:"error"`
 	Aggregations map[string]interface{} `json:"aggregations"`
 	Hits         *SearchResponseHits    `json:"hits"`
@@ -101,20 +121,28 @@ type Query struct {
 
 // BoolQuery represents a bool query
 type BoolQuery struct {
-	Filters []Filter
+	Filters            []Filter
+	Must               []Filter
+	Should             []Filter
+	MustNot            []Filter
+	MinimumShouldMatch string
 }
 
 // MarshalJSON returns the JSON encoding of the boolean query.
 func (q *BoolQuery) MarshalJSON() ([]byte, error) {
 	root := make(map[string]interface{})
 
-	if len(q.Filters) > 0 {
-		if len(q.Filters) == 1 {
-			root["filter"] = q.Filters[0]
-		} else {
-			root["filter"] = q.Filters
+	for name, clause := range map[string][]Filter{"filter": q.Filters, "must": q.Must, "should": q.Should, "must_not": q.MustNot} {
+		if len(clause) == 1 {
+			root[name] = clause[0]
+		} else if len(clause) > 1 {
+			root[name] = clause
 		}
 	}
+
+	if q.MinimumShouldMatch != "" {
+		root["minimum_should_match"] = q.MinimumShouldMatch
+	}
 	return json.Marshal(root)
 }
 
@@ -140,36 +168,209 @@ func (f *QueryStringFilter) MarshalJSON() ([]byte, error) {
 	return json.Marshal(root)
 }
 
-// RangeFilter represents a range search filter
+// TermFilter represents a term search filter
+type TermFilter struct {
+	Filter
+	Key   string
+	Value interface{}
+}
+
+// MarshalJSON returns the JSON encoding of the term filter.
+func (f *TermFilter) MarshalJSON() ([]byte, error) {
+	root := map[string]interface{}{
+		"term": map[string]interface{}{
+			f.Key: f.Value,
+		},
+	}
+
+	return json.Marshal(root)
+}
+
+// TermsFilter represents a terms search filter, which matches any of the values
+type TermsFilter struct {
+	Filter
+	Key    string
+	Values []interface{}
+}
+
+// MarshalJSON returns the JSON encoding of the terms filter.
+func (f *TermsFilter) MarshalJSON() ([]byte, error) {
+	root := map[string]interface{}{
+		"terms": map[string]interface{}{
+			f.Key: f.Values,
+		},
+	}
+
+	return json.Marshal(root)
+}
+
+// RangeFilter represents a range search filter, only the bounds that are set are part of the filter
 type RangeFilter struct {
 	Filter
 	Key    string
-	Gte    string
-	Lte    string
+	Gte    interface{}
+	Lte    interface{}
+	Gt     interface{}
+	Lt     interface{}
 	Format string
 }
 
 // DateFormatEpochMS represents a date format of epoch milliseconds (epoch_millis)
 const DateFormatEpochMS = "epoch_millis"
 
-// MarshalJSON returns the JSON encoding of the query string filter.
+// MarshalJSON returns the JSON encoding of the range filter.
 func (f *RangeFilter) MarshalJSON() ([]byte, error) {
+	bounds := make(map[string]interface{})
+	for name, bound := range map[string]interface{}{"lte": f.Lte, "gte": f.Gte, "lt": f.Lt, "gt": f.Gt} {
+		if bound != nil && bound != "" {
+			bounds[name] = bound
+		}
+	}
+
+	if f.Format != "" {
+		bounds["format"] = f.Format
+	}
+
 	root := map[string]map[string]map[string]interface{}{
 		"range": {
-			f.Key: {
-				"lte": f.Lte,
-				"gte": f.Gte,
-			},
+			f.Key: bounds,
 		},
 	}
 
-	if f.Format != "" {
-		root["range"][f.Key]["format"] = f.Format
+	return json.Marshal(root)
+}
+
+// MatchFilter represents a match search filter
+type MatchFilter struct {
+	Filter
+	Key   string
+	Value interface{}
+}
+
+// MarshalJSON returns the JSON encoding of the match filter.
+func (f *MatchFilter) MarshalJSON() ([]byte, error) {
+	return marshalFieldQuery("match", f.Key, "query", f.Value)
+}
+
+// MatchPhraseFilter represents a match phrase search filter
+type MatchPhraseFilter struct {
+	Filter
+	Key   string
+	Value string
+}
+
+// MarshalJSON returns the JSON encoding of the match phrase filter.
+func (f *MatchPhraseFilter) MarshalJSON() ([]byte, error) {
+	return marshalFieldQuery("match_phrase", f.Key, "query", f.Value)
+}
+
+// ExistsFilter represents a filter matching the documents with a value for the field
+type ExistsFilter struct {
+	Filter
+	Field string
+}
+
+// MarshalJSON returns the JSON encoding of the exists filter.
+func (f *ExistsFilter) MarshalJSON() ([]byte, error) {
+	root := map[string]interface{}{
+		"exists": map[string]interface{}{
+			"field": f.Field,
+		},
+	}
+
+	return json.Marshal(root)
+}
+
+// PrefixFilter represents a prefix search filter
+type PrefixFilter struct {
+	Filter
+	Key   string
+	Value string
+}
+
+// MarshalJSON returns the JSON encoding of the prefix filter.
+func (f *PrefixFilter) MarshalJSON() ([]byte, error) {
+	return marshalFieldQuery("prefix", f.Key, "value", f.Value)
+}
+
+// WildcardFilter represents a wildcard search filter
+type WildcardFilter struct {
+	Filter
+	Key   string
+	Value string
+}
+
+// MarshalJSON returns the JSON encoding of the wildcard filter.
+func (f *WildcardFilter) MarshalJSON() ([]byte, error) {
+	return marshalFieldQuery("wildcard", f.Key, "value", f.Value)
+}
+
+// RegexpFilter represents a regular expression search filter
+type RegexpFilter struct {
+	Filter
+	Key   string
+	Value string
+}
+
+// MarshalJSON returns the JSON encoding of the regular expression filter.
+func (f *RegexpFilter) MarshalJSON() ([]byte, error) {
+	return marshalFieldQuery("regexp", f.Key, "value", f.Value)
+}
+
+// BoolFilter represents a bool query nested in a clause of another bool query
+type BoolFilter struct {
+	Filter
+	Query *BoolQuery
+}
+
+// MarshalJSON returns the JSON encoding of the nested bool query.
+func (f *BoolFilter) MarshalJSON() ([]byte, error) {
+	root := map[string]interface{}{
+		"bool": f.Query,
 	}
 
 	return json.Marshal(root)
 }
 
+// marshalFieldQuery returns the JSON encoding of a query of a single field, e.g. {"prefix":{"host":{"value":"server"}}}
+func marshalFieldQuery(queryType, key, param string, value interface{}) ([]byte, error) {
+	root := map[string]interface{}{
+		queryType: map[string]interface{}{
+			key: map[string]interface{}{
+				param: value,
+			},
+		},
+	}
+
+	return json.Marshal(root)
+}
+
+// AdHocFilter represents an ad hoc filter of a dashboard
+type AdHocFilter struct {
+	Key      string
+	Operator string
+	Value    string
+}
+
+// Ad hoc filter operators
+const (
+	AdHocFilterEqual    = "="
+	AdHocFilterNotEqual = "!="
+	AdHocFilterLess     = "<"
+	AdHocFilterGreater  = ">"
+	AdHocFilterRegex    = "=~"
+	AdHocFilterNotRegex = "!~"
+)
+
+// IsAdHocFilterOperator checks whether operator is one of the ad hoc filter operators
+func IsAdHocFilterOperator(operator string) bool {
+	switch operator {
+	case AdHocFilterEqual, AdHocFilterNotEqual, AdHocFilterLess, AdHocFilterGreater, AdHocFilterRegex, AdHocFilterNotRegex:
+		return true
+	}
+	return false
+}
+
 // Aggregation represents an aggregation
 type Aggregation interface{}
 
@@ -245,13 +446,71 @@ type HistogramAgg struct {
 
 // DateHistogramAgg represents a date histogram aggregation
 type DateHistogramAgg struct {
-	Field          string          `json:"field"`
-	Interval       string          `json:"interval,omitempty"`
-	MinDocCount    int             `json:"min_doc_count"`
-	Missing        *string         `json:"missing,omitempty"`
-	ExtendedBounds *ExtendedBounds `json:"extended_bounds"`
-	Format         string          `json:"format"`
-	Offset         string          `json:"offset,omitempty"`
+	Field            string          `json:"field"`
+	Interval         string          `json:"interval,omitempty"`
+	FixedInterval    string          `json:"fixed_interval,omitempty"`
+	CalendarInterval string          `json:"calendar_interval,omitempty"`
+	MinDocCount      int             `json:"min_doc_count"`
+	Missing          *string         `json:"missing,omitempty"`
+	ExtendedBounds   *ExtendedBounds `json:"extended_bounds"`
+	Format           string          `json:"format"`
+	Offset           string          `json:"offset,omitempty"`
+	TimeZone         string          `json:"time_zone,omitempty"`
+}
+
+// AutoDateHistogramAgg represents an auto date histogram aggregation, which picks the interval that
+// returns at most the given number of buckets
+type AutoDateHistogramAgg struct {
+	Field           string  `json:"field"`
+	Buckets         int     `json:"buckets,omitempty"`
+	MinimumInterval string  `json:"minimum_interval,omitempty"`
+	Missing         *string `json:"missing,omitempty"`
+	Format          string  `json:"format"`
+	TimeZone        string  `json:"time_zone,omitempty"`
+}
+
+// RangeAggregation represents a range, date_range or ip_range aggregation
+type RangeAggregation struct {
+	Field    string             `json:"field"`
+	Ranges   []AggregationRange `json:"ranges"`
+	Keyed    bool               `json:"keyed,omitempty"`
+	Format   string             `json:"format,omitempty"`
+	TimeZone string             `json:"time_zone,omitempty"`
+}
+
+// AggregationRange represents a range of a range aggregation, the bounds are numbers, dates or IP
+// addresses and the ranges of an ip_range aggregation can be a CIDR mask instead
+type AggregationRange struct {
+	Key  string      `json:"key,omitempty"`
+	From interface{} `json:"from,omitempty"`
+	To   interface{} `json:"to,omitempty"`
+	Mask string      `json:"mask,omitempty"`
+}
+
+// CompositeAggregation represents a composite aggregation, which returns the buckets of all the
+// combinations of its sources by pages, the buckets after the After key being the next page
+type CompositeAggregation struct {
+	Size    int                    `json:"size"`
+	Sources []CompositeSource      `json:"sources"`
+	After   map[string]interface{} `json:"after,omitempty"`
+	// MaxBuckets is the number of buckets the pages are fetched up to, it isn't sent to the cluster
+	MaxBuckets int `json:"-"`
+}
+
+// CompositeSource represents a source of the keys of a composite aggregation
+type CompositeSource struct {
+	Name  string
+	Type  string
+	Field string
+}
+
+// MarshalJSON returns the JSON encoding of the composite source
+func (s CompositeSource) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		s.Name: map[string]interface{}{
+			s.Type: map[string]interface{}{"field": s.Field},
+		},
+	})
 }
 
 // FiltersAggregation represents a filters aggregation
@@ -259,6 +518,16 @@ type FiltersAggregation struct {
 	Filters map[string]interface{} `json:"filters"`
 }
 
+// FilterAggregation represents a filter aggregation
+type FilterAggregation struct {
+	Filter Filter
+}
+
+// MarshalJSON returns the JSON encoding of the filter aggregation
+func (a *FilterAggregation) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.Filter)
+}
+
 // TermsAggregation represents a terms aggregation
 type TermsAggregation struct {
 	Field       string                 `json:"field"`
@@ -268,6 +537,35 @@ type TermsAggregation struct {
 	Missing     *string                `json:"missing,omitempty"`
 }
 
+// MultiTermsAggregation represents a multi terms aggregation, whose buckets are keyed by the terms of
+// several fields
+type MultiTermsAggregation struct {
+	Terms       []MultiTermsSource     `json:"terms"`
+	Size        int                    `json:"size"`
+	Order       map[string]interface{} `json:"order,omitempty"`
+	MinDocCount *int                   `json:"min_doc_count,omitempty"`
+}
+
+// MultiTermsSource represents a field of the terms of a multi terms aggregation
+type MultiTermsSource struct {
+	Field string `json:"field"`
+}
+
+// RareTermsAggregation represents a rare terms aggregation, which returns the terms that are in at
+// most MaxDocCount documents
+type RareTermsAggregation struct {
+	Field       string  `json:"field"`
+	MaxDocCount int     `json:"max_doc_count,omitempty"`
+	Missing     *string `json:"missing,omitempty"`
+}
+
+// SignificantTermsAggregation represents a significant terms aggregation
+type SignificantTermsAggregation struct {
+	Field       string `json:"field"`
+	Size        int    `json:"size,omitempty"`
+	MinDocCount *int   `json:"min_doc_count,omitempty"`
+}
+
 // ExtendedBounds represents extended bounds
 type ExtendedBounds struct {
 	Min string `json:"min"`
@@ -280,6 +578,18 @@ type GeoHashGridAggregation struct {
 	Precision int    `json:"precision"`
 }
 
+// GeoTileGridAggregation represents a geo tile grid aggregation
+type GeoTileGridAggregation struct {
+	Field     string `json:"field"`
+	Precision int    `json:"precision"`
+}
+
+// GeoHexGridAggregation represents a geo hex grid aggregation
+type GeoHexGridAggregation struct {
+	Field     string `json:"field"`
+	Precision int    `json:"precision"`
+}
+
 // MetricAggregation represents a metric aggregation
 type MetricAggregation struct {
 	Field    string
@@ -288,8 +598,11 @@ type MetricAggregation struct {
 
 // MarshalJSON returns the JSON encoding of the metric aggregation
 func (a *MetricAggregation) MarshalJSON() ([]byte, error) {
-	root := map[string]interface{}{
-		"field": a.Field,
+	root := map[string]interface{}{}
+
+	// script based metrics have no field
+	if a.Field != "" {
+		root["field"] = a.Field
 	}
 
 	for k, v := range a.Settings {
@@ -309,8 +622,11 @@ type PipelineAggregation struct {
 
 // MarshalJSON returns the JSON encoding of the pipeline aggregation
 func (a *PipelineAggregation) MarshalJSON() ([]byte, error) {
-	root := map[string]interface{}{
-		"buckets_path": a.BucketPath,
+	root := map[string]interface{}{}
+
+	// bucket_sort sorts the buckets of its parent and has no bucket path
+	if a.BucketPath != nil {
+		root["buckets_path"] = a.BucketPath
 	}
 
 	for k, v := range a.Settings {
@@ -345,7 +661,8 @@ type PPLDebugInfo struct {
 
 // PPLRequest represents the PPL query object.
 type PPLRequest struct {
-	Query string
+	Query     string
+	FetchSize int
 }
 
 // MarshalJSON returns the JSON encoding of the PPL query string filter.
@@ -353,16 +670,62 @@ func (req *PPLRequest) MarshalJSON() ([]byte, error) {
 	root := map[string]interface{}{
 		"query": req.Query,
 	}
+	if req.FetchSize > 0 {
+		root["fetch_size"] = req.FetchSize
+	}
 
 	return json.Marshal(root)
 }
 
-// PPLResponse represents a PPL response
+// SQLRequest represents the SQL query object.
+type SQLRequest struct {
+	Query     string
+	FetchSize int
+}
+
+// MarshalJSON returns the JSON encoding of the SQL query.
+func (req *SQLRequest) MarshalJSON() ([]byte, error) {
+	root := map[string]interface{}{
+		"query": req.Query,
+	}
+	if req.FetchSize > 0 {
+		root["fetch_size"] = req.FetchSize
+	}
+
+	return json.Marshal(root)
+}
+
+// CursorRequest represents the request of the next page of a PPL or SQL response
+type CursorRequest struct {
+	Cursor string
+}
+
+// MarshalJSON returns the JSON encoding of the cursor request.
+func (req *CursorRequest) MarshalJSON() ([]byte, error) {
+	root := map[string]interface{}{
+		"cursor": req.Cursor,
+	}
+
+	return json.Marshal(root)
+}
+
+// QueryLanguage is the language of a query sent to the SQL plugin
+type QueryLanguage string
+
+const (
+	LanguagePPL QueryLanguage = "PPL"
+	LanguageSQL QueryLanguage = "SQL"
+)
+
+// PPLResponse represents a PPL response, SQL queries have the same response format
 type PPLResponse struct {
 	Status    int                    `json:"status,omitempty"`
 	Error     map[string]interface{} `json:"error"`
 	Schema    []FieldSchema          `json:"schema"`
 	Datarows  []Datarow              `json:"datarows"`
+	Total     int                    `json:"total"`
+	Size      int                    `json:"size"`
+	Cursor    string                 `json:"cursor"`
 	DebugInfo *PPLDebugInfo          `json:"-"`
 }
 
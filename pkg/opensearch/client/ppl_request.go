@@ -7,8 +7,9 @@ import (
 
 // PPLRequestBuilder represents a PPL request builder
 type PPLRequestBuilder struct {
-	index    string
-	pplQuery string
+	index     string
+	pplQuery  string
+	fetchSize int
 }
 
 // NewPPLRequestBuilder create a new PPL request builder
@@ -22,10 +23,18 @@ func NewPPLRequestBuilder(index string) *PPLRequestBuilder {
 // Build builds and return a PPL query object
 func (b *PPLRequestBuilder) Build() (*PPLRequest, error) {
 	return &PPLRequest{
-		Query: b.pplQuery,
+		Query:     b.pplQuery,
+		FetchSize: b.fetchSize,
 	}, nil
 }
 
+// FetchSize sets the number of rows of the first page of the response, the response has a cursor
+// to fetch the next pages when there are more rows
+func (b *PPLRequestBuilder) FetchSize(size int) *PPLRequestBuilder {
+	b.fetchSize = size
+	return b
+}
+
 // AddPPLQueryString adds a new PPL query string with time range filter
 func (b *PPLRequestBuilder) AddPPLQueryString(timeField, to, from, querystring string) *PPLRequestBuilder {
 	var res []string
@@ -46,3 +55,31 @@ func (b *PPLRequestBuilder) AddPPLQueryString(timeField, to, from, querystring s
 	b.pplQuery = strings.Join(res, " |")
 	return b
 }
+
+// AddAdHocFilters adds a where command with the conditions of the ad hoc filters to the end of the
+// query, the filters with an unknown operator are ignored
+func (b *PPLRequestBuilder) AddAdHocFilters(adHocFilters []AdHocFilter) *PPLRequestBuilder {
+	conditions := make([]string, 0, len(adHocFilters))
+	for _, f := range adHocFilters {
+		field := fmt.Sprintf("`%s`", f.Key)
+		value := quotePPLString(f.Value)
+		switch f.Operator {
+		case AdHocFilterEqual, AdHocFilterNotEqual, AdHocFilterLess, AdHocFilterGreater:
+			conditions = append(conditions, fmt.Sprintf("%s %s %s", field, f.Operator, value))
+		case AdHocFilterRegex:
+			conditions = append(conditions, fmt.Sprintf("%s regexp %s", field, value))
+		case AdHocFilterNotRegex:
+			conditions = append(conditions, fmt.Sprintf("not %s regexp %s", field, value))
+		}
+	}
+
+	if len(conditions) > 0 {
+		b.pplQuery = fmt.Sprintf("%s | where %s", b.pplQuery, strings.Join(conditions, " and "))
+	}
+	return b
+}
+
+// quotePPLString returns s as a PPL string literal
+func quotePPLString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -26,6 +26,21 @@ func TestPPLRequest(t *testing.T) {
 					json, err := simplejson.NewJson(body)
 					So(err, ShouldBeNil)
 					So(json.Get("query").Interface(), ShouldEqual, "")
+					_, hasFetchSize := json.CheckGet("fetch_size")
+					So(hasFetchSize, ShouldBeFalse)
+				})
+			})
+
+			Convey("When setting the fetch size", func() {
+				pr, err := b.FetchSize(500).Build()
+				So(err, ShouldBeNil)
+
+				Convey("When marshal to JSON should include the fetch size", func() {
+					body, err := json.Marshal(pr)
+					So(err, ShouldBeNil)
+					json, err := simplejson.NewJson(body)
+					So(err, ShouldBeNil)
+					So(json.Get("fetch_size").MustInt(), ShouldEqual, 500)
 				})
 			})
 
@@ -71,6 +86,33 @@ func TestPPLRequest(t *testing.T) {
 					})
 				})
 			})
+			Convey("When adding ad hoc filters", func() {
+				b.AddPPLQueryString(timeField, "$timeTo", "$timeFrom", "source = index | fields host, path")
+				b.AddAdHocFilters([]AdHocFilter{
+					{Key: "host", Operator: "=", Value: "O'Brien"},
+					{Key: "bytes", Operator: ">", Value: "10"},
+					{Key: "path", Operator: "=~", Value: "/api/.*"},
+					{Key: "path", Operator: "!~", Value: "/health.*"},
+				})
+
+				Convey("Should add the conditions in a where command at the end of the query", func() {
+					pr, err := b.Build()
+					So(err, ShouldBeNil)
+					So(pr.Query, ShouldEqual, "source = index | where `@timestamp` >= timestamp('$timeFrom') and `@timestamp` <= timestamp('$timeTo') | fields host, path"+
+						" | where `host` = 'O''Brien' and `bytes` > '10' and `path` regexp '/api/.*' and not `path` regexp '/health.*'")
+				})
+			})
+
+			Convey("When adding no ad hoc filters", func() {
+				b.AddPPLQueryString(timeField, "$timeTo", "$timeFrom", "source = index")
+				b.AddAdHocFilters(nil)
+
+				Convey("Should not change the query", func() {
+					pr, err := b.Build()
+					So(err, ShouldBeNil)
+					So(pr.Query, ShouldEqual, "source = index | where `@timestamp` >= timestamp('$timeFrom') and `@timestamp` <= timestamp('$timeTo')")
+				})
+			})
 		})
 	})
 }
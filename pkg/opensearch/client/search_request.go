@@ -74,8 +74,13 @@ func (b *SearchRequestBuilder) Size(size int) *SearchRequestBuilder {
 
 // SortDesc adds a sort to the search request
 func (b *SearchRequestBuilder) SortDesc(field, unmappedType string) *SearchRequestBuilder {
+	return b.Sort("desc", field, unmappedType)
+}
+
+// Sort adds a sort in the given order, asc or desc, to the search request
+func (b *SearchRequestBuilder) Sort(order, field, unmappedType string) *SearchRequestBuilder {
 	props := map[string]string{
-		"order": "desc",
+		"order": order,
 	}
 
 	if unmappedType != "" {
@@ -101,6 +106,31 @@ func (b *SearchRequestBuilder) AddDocValueField(field string) *SearchRequestBuil
 	return b
 }
 
+// CustomProp sets a property of the search request body that the builder has no method for
+func (b *SearchRequestBuilder) CustomProp(key string, value interface{}) *SearchRequestBuilder {
+	b.customProps[key] = value
+	return b
+}
+
+// Highlight tags used to mark matching terms in log lines
+const (
+	HighlightPreTag  = "@HIGHLIGHT@"
+	HighlightPostTag = "@/HIGHLIGHT@"
+)
+
+// AddHighlight adds highlighting of all matching fields to the search request
+func (b *SearchRequestBuilder) AddHighlight() *SearchRequestBuilder {
+	b.customProps["highlight"] = map[string]interface{}{
+		"fields": map[string]interface{}{
+			"*": map[string]interface{}{},
+		},
+		"pre_tags":      []string{HighlightPreTag},
+		"post_tags":     []string{HighlightPostTag},
+		"fragment_size": 2147483647,
+	}
+	return b
+}
+
 // Query creates and return a query builder
 func (b *SearchRequestBuilder) Query() *QueryBuilder {
 	if b.queryBuilder == nil {
@@ -189,7 +219,11 @@ func (b *QueryBuilder) Bool() *BoolQueryBuilder {
 
 // BoolQueryBuilder represents a bool query builder
 type BoolQueryBuilder struct {
-	filterQueryBuilder *FilterQueryBuilder
+	filterQueryBuilder  *FilterQueryBuilder
+	mustQueryBuilder    *FilterQueryBuilder
+	shouldQueryBuilder  *FilterQueryBuilder
+	mustNotQueryBuilder *FilterQueryBuilder
+	minimumShouldMatch  string
 }
 
 // NewBoolQueryBuilder create a new bool query builder
@@ -205,22 +239,66 @@ func (b *BoolQueryBuilder) Filter() *FilterQueryBuilder {
 	return b.filterQueryBuilder
 }
 
+// Must creates and return a query builder of the queries the documents must match, which contribute to the score
+func (b *BoolQueryBuilder) Must() *FilterQueryBuilder {
+	if b.mustQueryBuilder == nil {
+		b.mustQueryBuilder = NewFilterQueryBuilder()
+	}
+	return b.mustQueryBuilder
+}
+
+// Should creates and return a query builder of the queries the documents should match
+func (b *BoolQueryBuilder) Should() *FilterQueryBuilder {
+	if b.shouldQueryBuilder == nil {
+		b.shouldQueryBuilder = NewFilterQueryBuilder()
+	}
+	return b.shouldQueryBuilder
+}
+
+// MustNot creates and return a query builder of the queries the documents must not match
+func (b *BoolQueryBuilder) MustNot() *FilterQueryBuilder {
+	if b.mustNotQueryBuilder == nil {
+		b.mustNotQueryBuilder = NewFilterQueryBuilder()
+	}
+	return b.mustNotQueryBuilder
+}
+
+// MinimumShouldMatch sets the number or percentage of the should queries the documents must match
+func (b *BoolQueryBuilder) MinimumShouldMatch(minimumShouldMatch string) *BoolQueryBuilder {
+	b.minimumShouldMatch = minimumShouldMatch
+	return b
+}
+
 // Build builds and return a bool query builder
 func (b *BoolQueryBuilder) Build() (*BoolQuery, error) {
-	boolQuery := BoolQuery{}
-
-	if b.filterQueryBuilder != nil {
-		filters, err := b.filterQueryBuilder.Build()
+	boolQuery := BoolQuery{
+		MinimumShouldMatch: b.minimumShouldMatch,
+	}
+
+	clauses := []struct {
+		builder *FilterQueryBuilder
+		filters *[]Filter
+	}{
+		{b.filterQueryBuilder, &boolQuery.Filters},
+		{b.mustQueryBuilder, &boolQuery.Must},
+		{b.shouldQueryBuilder, &boolQuery.Should},
+		{b.mustNotQueryBuilder, &boolQuery.MustNot},
+	}
+	for _, clause := range clauses {
+		if clause.builder == nil {
+			continue
+		}
+		filters, err := clause.builder.Build()
 		if err != nil {
 			return nil, err
 		}
-		boolQuery.Filters = filters
+		*clause.filters = filters
 	}
 
 	return &boolQuery, nil
 }
 
-// FilterQueryBuilder represents a filter query builder
+// FilterQueryBuilder represents a builder of the queries of a bool query clause
 type FilterQueryBuilder struct {
 	filters []Filter
 }
@@ -234,7 +312,19 @@ func NewFilterQueryBuilder() *FilterQueryBuilder {
 
 // Build builds and return a filter query builder
 func (b *FilterQueryBuilder) Build() ([]Filter, error) {
-	return b.filters, nil
+	filters := make([]Filter, 0, len(b.filters))
+	for _, f := range b.filters {
+		// nested bool queries are built with the clause
+		if nested, ok := f.(*BoolQueryBuilder); ok {
+			boolQuery, err := nested.Build()
+			if err != nil {
+				return nil, err
+			}
+			f = &BoolFilter{Query: boolQuery}
+		}
+		filters = append(filters, f)
+	}
+	return filters, nil
 }
 
 // AddDateRangeFilter adds a new time range filter
@@ -248,6 +338,105 @@ func (b *FilterQueryBuilder) AddDateRangeFilter(timeField, lte, gte, format stri
 	return b
 }
 
+// AddTermFilter adds a new term filter
+func (b *FilterQueryBuilder) AddTermFilter(key string, value interface{}) *FilterQueryBuilder {
+	b.filters = append(b.filters, &TermFilter{
+		Key:   key,
+		Value: value,
+	})
+	return b
+}
+
+// AddTermsFilter adds a new terms filter matching any of the values
+func (b *FilterQueryBuilder) AddTermsFilter(key string, values []interface{}) *FilterQueryBuilder {
+	b.filters = append(b.filters, &TermsFilter{
+		Key:    key,
+		Values: values,
+	})
+	return b
+}
+
+// AddRangeFilter adds a new range filter with the bounds set by fn
+func (b *FilterQueryBuilder) AddRangeFilter(key string, fn func(f *RangeFilter)) *FilterQueryBuilder {
+	f := &RangeFilter{
+		Key: key,
+	}
+	if fn != nil {
+		fn(f)
+	}
+	b.filters = append(b.filters, f)
+	return b
+}
+
+// AddMatchFilter adds a new match filter
+func (b *FilterQueryBuilder) AddMatchFilter(key string, value interface{}) *FilterQueryBuilder {
+	b.filters = append(b.filters, &MatchFilter{
+		Key:   key,
+		Value: value,
+	})
+	return b
+}
+
+// AddMatchPhraseFilter adds a new match phrase filter
+func (b *FilterQueryBuilder) AddMatchPhraseFilter(key, value string) *FilterQueryBuilder {
+	b.filters = append(b.filters, &MatchPhraseFilter{
+		Key:   key,
+		Value: value,
+	})
+	return b
+}
+
+// AddExistsFilter adds a new filter matching the documents with a value for the field
+func (b *FilterQueryBuilder) AddExistsFilter(field string) *FilterQueryBuilder {
+	b.filters = append(b.filters, &ExistsFilter{
+		Field: field,
+	})
+	return b
+}
+
+// AddPrefixFilter adds a new prefix filter
+func (b *FilterQueryBuilder) AddPrefixFilter(key, value string) *FilterQueryBuilder {
+	b.filters = append(b.filters, &PrefixFilter{
+		Key:   key,
+		Value: value,
+	})
+	return b
+}
+
+// AddWildcardFilter adds a new wildcard filter
+func (b *FilterQueryBuilder) AddWildcardFilter(key, value string) *FilterQueryBuilder {
+	b.filters = append(b.filters, &WildcardFilter{
+		Key:   key,
+		Value: value,
+	})
+	return b
+}
+
+// AddRegexpFilter adds a new regular expression filter
+func (b *FilterQueryBuilder) AddRegexpFilter(key, value string) *FilterQueryBuilder {
+	b.filters = append(b.filters, &RegexpFilter{
+		Key:   key,
+		Value: value,
+	})
+	return b
+}
+
+// AddBoolFilter adds a new bool query with the clauses added by fn
+func (b *FilterQueryBuilder) AddBoolFilter(fn func(b *BoolQueryBuilder)) *FilterQueryBuilder {
+	nested := NewBoolQueryBuilder()
+	if fn != nil {
+		fn(nested)
+	}
+	b.filters = append(b.filters, nested)
+	return b
+}
+
+// AddRawQuery adds a query given as its query DSL, e.g. {"function_score": {...}}
+func (b *FilterQueryBuilder) AddRawQuery(query map[string]interface{}) *FilterQueryBuilder {
+	b.filters = append(b.filters, query)
+	return b
+}
+
 // AddQueryStringFilter adds a new query string filter
 func (b *FilterQueryBuilder) AddQueryStringFilter(querystring string, analyzeWildcard bool) *FilterQueryBuilder {
 	if len(strings.TrimSpace(querystring)) == 0 {
@@ -261,13 +450,46 @@ func (b *FilterQueryBuilder) AddQueryStringFilter(querystring string, analyzeWil
 	return b
 }
 
+// AddAdHocFilters adds a filter for each ad hoc filter, the filters with an unknown operator are ignored
+func (b *FilterQueryBuilder) AddAdHocFilters(adHocFilters []AdHocFilter) *FilterQueryBuilder {
+	for _, f := range adHocFilters {
+		key, value := f.Key, f.Value
+		switch f.Operator {
+		case AdHocFilterEqual:
+			b.AddMatchPhraseFilter(key, value)
+		case AdHocFilterNotEqual:
+			b.AddBoolFilter(func(b *BoolQueryBuilder) { b.MustNot().AddMatchPhraseFilter(key, value) })
+		case AdHocFilterLess:
+			b.AddRangeFilter(key, func(f *RangeFilter) { f.Lt = value })
+		case AdHocFilterGreater:
+			b.AddRangeFilter(key, func(f *RangeFilter) { f.Gt = value })
+		case AdHocFilterRegex:
+			b.AddRegexpFilter(key, value)
+		case AdHocFilterNotRegex:
+			b.AddBoolFilter(func(b *BoolQueryBuilder) { b.MustNot().AddRegexpFilter(key, value) })
+		}
+	}
+	return b
+}
+
 // AggBuilder represents an aggregation builder
 type AggBuilder interface {
 	Histogram(key, field string, fn func(a *HistogramAgg, b AggBuilder)) AggBuilder
 	DateHistogram(key, field string, fn func(a *DateHistogramAgg, b AggBuilder)) AggBuilder
 	Terms(key, field string, fn func(a *TermsAggregation, b AggBuilder)) AggBuilder
 	Filters(key string, fn func(a *FiltersAggregation, b AggBuilder)) AggBuilder
+	Filter(key string, filter Filter, fn func(a *FilterAggregation, b AggBuilder)) AggBuilder
 	GeoHashGrid(key, field string, fn func(a *GeoHashGridAggregation, b AggBuilder)) AggBuilder
+	GeoTileGrid(key, field string, fn func(a *GeoTileGridAggregation, b AggBuilder)) AggBuilder
+	GeoHexGrid(key, field string, fn func(a *GeoHexGridAggregation, b AggBuilder)) AggBuilder
+	Range(key, field string, fn func(a *RangeAggregation, b AggBuilder)) AggBuilder
+	DateRange(key, field string, fn func(a *RangeAggregation, b AggBuilder)) AggBuilder
+	IPRange(key, field string, fn func(a *RangeAggregation, b AggBuilder)) AggBuilder
+	AutoDateHistogram(key, field string, fn func(a *AutoDateHistogramAgg, b AggBuilder)) AggBuilder
+	Composite(key string, fn func(a *CompositeAggregation, b AggBuilder)) AggBuilder
+	MultiTerms(key string, fields []string, fn func(a *MultiTermsAggregation, b AggBuilder)) AggBuilder
+	RareTerms(key, field string, fn func(a *RareTermsAggregation, b AggBuilder)) AggBuilder
+	SignificantTerms(key, field string, fn func(a *SignificantTermsAggregation, b AggBuilder)) AggBuilder
 	Metric(key, metricType, field string, fn func(a *MetricAggregation)) AggBuilder
 	Pipeline(key, pipelineType string, bucketPath interface{}, fn func(a *PipelineAggregation)) AggBuilder
 	Build() (AggArray, error)
@@ -381,6 +603,70 @@ func (b *aggBuilderImpl) Terms(key, field string, fn func(a *TermsAggregation, b
 	return b
 }
 
+func (b *aggBuilderImpl) MultiTerms(key string, fields []string, fn func(a *MultiTermsAggregation, b AggBuilder)) AggBuilder {
+	innerAgg := &MultiTermsAggregation{
+		Terms: make([]MultiTermsSource, 0, len(fields)),
+		Order: make(map[string]interface{}),
+	}
+	for _, field := range fields {
+		innerAgg.Terms = append(innerAgg.Terms, MultiTermsSource{Field: field})
+	}
+	aggDef := newAggDef(key, &aggContainer{
+		Type:        "multi_terms",
+		Aggregation: innerAgg,
+	})
+
+	if fn != nil {
+		builder := newAggBuilder(b.version)
+		aggDef.builders = append(aggDef.builders, builder)
+		fn(innerAgg, builder)
+	}
+
+	b.aggDefs = append(b.aggDefs, aggDef)
+
+	return b
+}
+
+func (b *aggBuilderImpl) RareTerms(key, field string, fn func(a *RareTermsAggregation, b AggBuilder)) AggBuilder {
+	innerAgg := &RareTermsAggregation{
+		Field: field,
+	}
+	aggDef := newAggDef(key, &aggContainer{
+		Type:        "rare_terms",
+		Aggregation: innerAgg,
+	})
+
+	if fn != nil {
+		builder := newAggBuilder(b.version)
+		aggDef.builders = append(aggDef.builders, builder)
+		fn(innerAgg, builder)
+	}
+
+	b.aggDefs = append(b.aggDefs, aggDef)
+
+	return b
+}
+
+func (b *aggBuilderImpl) SignificantTerms(key, field string, fn func(a *SignificantTermsAggregation, b AggBuilder)) AggBuilder {
+	innerAgg := &SignificantTermsAggregation{
+		Field: field,
+	}
+	aggDef := newAggDef(key, &aggContainer{
+		Type:        "significant_terms",
+		Aggregation: innerAgg,
+	})
+
+	if fn != nil {
+		builder := newAggBuilder(b.version)
+		aggDef.builders = append(aggDef.builders, builder)
+		fn(innerAgg, builder)
+	}
+
+	b.aggDefs = append(b.aggDefs, aggDef)
+
+	return b
+}
+
 func (b *aggBuilderImpl) Filters(key string, fn func(a *FiltersAggregation, b AggBuilder)) AggBuilder {
 	innerAgg := &FiltersAggregation{
 		Filters: make(map[string]interface{}),
@@ -400,6 +686,25 @@ func (b *aggBuilderImpl) Filters(key string, fn func(a *FiltersAggregation, b Ag
 	return b
 }
 
+func (b *aggBuilderImpl) Filter(key string, filter Filter, fn func(a *FilterAggregation, b AggBuilder)) AggBuilder {
+	innerAgg := &FilterAggregation{
+		Filter: filter,
+	}
+	aggDef := newAggDef(key, &aggContainer{
+		Type:        "filter",
+		Aggregation: innerAgg,
+	})
+	if fn != nil {
+		builder := newAggBuilder(b.version)
+		aggDef.builders = append(aggDef.builders, builder)
+		fn(innerAgg, builder)
+	}
+
+	b.aggDefs = append(b.aggDefs, aggDef)
+
+	return b
+}
+
 func (b *aggBuilderImpl) GeoHashGrid(key, field string, fn func(a *GeoHashGridAggregation, b AggBuilder)) AggBuilder {
 	innerAgg := &GeoHashGridAggregation{
 		Field:     field,
@@ -421,6 +726,121 @@ func (b *aggBuilderImpl) GeoHashGrid(key, field string, fn func(a *GeoHashGridAg
 	return b
 }
 
+func (b *aggBuilderImpl) GeoTileGrid(key, field string, fn func(a *GeoTileGridAggregation, b AggBuilder)) AggBuilder {
+	innerAgg := &GeoTileGridAggregation{
+		Field:     field,
+		Precision: 7,
+	}
+	aggDef := newAggDef(key, &aggContainer{
+		Type:        "geotile_grid",
+		Aggregation: innerAgg,
+	})
+
+	if fn != nil {
+		builder := newAggBuilder(b.version)
+		aggDef.builders = append(aggDef.builders, builder)
+		fn(innerAgg, builder)
+	}
+
+	b.aggDefs = append(b.aggDefs, aggDef)
+
+	return b
+}
+
+func (b *aggBuilderImpl) GeoHexGrid(key, field string, fn func(a *GeoHexGridAggregation, b AggBuilder)) AggBuilder {
+	innerAgg := &GeoHexGridAggregation{
+		Field:     field,
+		Precision: 5,
+	}
+	aggDef := newAggDef(key, &aggContainer{
+		Type:        "geohex_grid",
+		Aggregation: innerAgg,
+	})
+
+	if fn != nil {
+		builder := newAggBuilder(b.version)
+		aggDef.builders = append(aggDef.builders, builder)
+		fn(innerAgg, builder)
+	}
+
+	b.aggDefs = append(b.aggDefs, aggDef)
+
+	return b
+}
+
+func (b *aggBuilderImpl) Range(key, field string, fn func(a *RangeAggregation, b AggBuilder)) AggBuilder {
+	return b.rangeAgg("range", key, field, fn)
+}
+
+func (b *aggBuilderImpl) DateRange(key, field string, fn func(a *RangeAggregation, b AggBuilder)) AggBuilder {
+	return b.rangeAgg("date_range", key, field, fn)
+}
+
+func (b *aggBuilderImpl) IPRange(key, field string, fn func(a *RangeAggregation, b AggBuilder)) AggBuilder {
+	return b.rangeAgg("ip_range", key, field, fn)
+}
+
+func (b *aggBuilderImpl) rangeAgg(rangeType, key, field string, fn func(a *RangeAggregation, b AggBuilder)) AggBuilder {
+	innerAgg := &RangeAggregation{
+		Field:  field,
+		Ranges: make([]AggregationRange, 0),
+	}
+	aggDef := newAggDef(key, &aggContainer{
+		Type:        rangeType,
+		Aggregation: innerAgg,
+	})
+
+	if fn != nil {
+		builder := newAggBuilder(b.version)
+		aggDef.builders = append(aggDef.builders, builder)
+		fn(innerAgg, builder)
+	}
+
+	b.aggDefs = append(b.aggDefs, aggDef)
+
+	return b
+}
+
+func (b *aggBuilderImpl) AutoDateHistogram(key, field string, fn func(a *AutoDateHistogramAgg, b AggBuilder)) AggBuilder {
+	innerAgg := &AutoDateHistogramAgg{
+		Field: field,
+	}
+	aggDef := newAggDef(key, &aggContainer{
+		Type:        "auto_date_histogram",
+		Aggregation: innerAgg,
+	})
+
+	if fn != nil {
+		builder := newAggBuilder(b.version)
+		aggDef.builders = append(aggDef.builders, builder)
+		fn(innerAgg, builder)
+	}
+
+	b.aggDefs = append(b.aggDefs, aggDef)
+
+	return b
+}
+
+func (b *aggBuilderImpl) Composite(key string, fn func(a *CompositeAggregation, b AggBuilder)) AggBuilder {
+	innerAgg := &CompositeAggregation{
+		Sources: make([]CompositeSource, 0),
+	}
+	aggDef := newAggDef(key, &aggContainer{
+		Type:        "composite",
+		Aggregation: innerAgg,
+	})
+
+	if fn != nil {
+		builder := newAggBuilder(b.version)
+		aggDef.builders = append(aggDef.builders, builder)
+		fn(innerAgg, builder)
+	}
+
+	b.aggDefs = append(b.aggDefs, aggDef)
+
+	return b
+}
+
 func (b *aggBuilderImpl) Metric(key, metricType, field string, fn func(a *MetricAggregation)) AggBuilder {
 	innerAgg := &MetricAggregation{
 		Field:    field,
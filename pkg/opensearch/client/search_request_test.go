@@ -104,6 +104,77 @@ func TestSearchRequest(t *testing.T) {
 				})
 			})
 
+			Convey("When adding the clauses of a bool query", func() {
+				q := b.Query().Bool()
+				q.Filter().
+					AddTermFilter("level", "error").
+					AddTermsFilter("host", []interface{}{"server1", "server2"}).
+					AddRangeFilter("bytes", func(f *RangeFilter) {
+						f.Gte = 10
+						f.Lt = 100
+					})
+				q.Must().
+					AddMatchFilter("message", "connection refused").
+					AddMatchPhraseFilter("message", "timed out")
+				q.Should().
+					AddPrefixFilter("path", "/api").
+					AddWildcardFilter("path", "/v?/*").
+					AddRegexpFilter("path", "/health.*")
+				q.MinimumShouldMatch("1")
+				q.MustNot().
+					AddExistsFilter("error").
+					AddBoolFilter(func(b *BoolQueryBuilder) {
+						b.Should().AddTermFilter("status", 500).AddTermFilter("status", 503)
+					})
+
+				Convey("When marshal to JSON should generate the clauses in a deterministic order", func() {
+					sr, err := b.Build()
+					So(err, ShouldBeNil)
+					body, err := json.Marshal(sr.Query)
+					So(err, ShouldBeNil)
+					So(string(body), ShouldEqual, `{"bool":{`+
+						`"filter":[{"term":{"level":"error"}},{"terms":{"host":["server1","server2"]}},{"range":{"bytes":{"gte":10,"lt":100}}}],`+
+						`"minimum_should_match":"1",`+
+						`"must":[{"match":{"message":{"query":"connection refused"}}},{"match_phrase":{"message":{"query":"timed out"}}}],`+
+						`"must_not":[{"exists":{"field":"error"}},{"bool":{"should":[{"term":{"status":500}},{"term":{"status":503}}]}}],`+
+						`"should":[{"prefix":{"path":{"value":"/api"}}},{"wildcard":{"path":{"value":"/v?/*"}}},{"regexp":{"path":{"value":"/health.*"}}}]`+
+						`}}`)
+
+					for i := 0; i < 10; i++ {
+						again, err := json.Marshal(sr.Query)
+						So(err, ShouldBeNil)
+						So(string(again), ShouldEqual, string(body))
+					}
+				})
+			})
+
+			Convey("When adding ad hoc filters", func() {
+				b.Query().Bool().Filter().AddAdHocFilters([]AdHocFilter{
+					{Key: "host", Operator: "=", Value: "server 1"},
+					{Key: "host", Operator: "!=", Value: "server 2"},
+					{Key: "bytes", Operator: "<", Value: "100"},
+					{Key: "bytes", Operator: ">", Value: "10"},
+					{Key: "path", Operator: "=~", Value: "/api/.*"},
+					{Key: "path", Operator: "!~", Value: "/health.*"},
+					{Key: "path", Operator: "<>", Value: "/"},
+				})
+
+				Convey("When marshal to JSON should generate a filter for each ad hoc filter", func() {
+					sr, err := b.Build()
+					So(err, ShouldBeNil)
+					body, err := json.Marshal(sr.Query)
+					So(err, ShouldBeNil)
+					So(string(body), ShouldEqual, `{"bool":{"filter":[`+
+						`{"match_phrase":{"host":{"query":"server 1"}}},`+
+						`{"bool":{"must_not":{"match_phrase":{"host":{"query":"server 2"}}}}},`+
+						`{"range":{"bytes":{"lt":"100"}}},`+
+						`{"range":{"bytes":{"gt":"10"}}},`+
+						`{"regexp":{"path":{"value":"/api/.*"}}},`+
+						`{"bool":{"must_not":{"regexp":{"path":{"value":"/health.*"}}}}}`+
+						`]}}`)
+				})
+			})
+
 			Convey("When adding doc value field", func() {
 				b.AddDocValueField(timeField)
 
@@ -392,6 +463,49 @@ func TestSearchRequest(t *testing.T) {
 			})
 		})
 
+		Convey("Given new search request builder with range aggs", func() {
+			version, _ := semver.NewVersion("1.0.0")
+			b := NewSearchRequestBuilder(OpenSearch, version, tsdb.Interval{Value: 15 * time.Second, Text: "15s"})
+			aggBuilder := b.Agg()
+			aggBuilder.Range("1", "latency", func(a *RangeAggregation, ib AggBuilder) {
+				a.Ranges = append(a.Ranges,
+					AggregationRange{Key: "fast", To: 100},
+					AggregationRange{Key: "slow", From: 100},
+				)
+				a.Keyed = true
+				ib.AutoDateHistogram("2", "@timestamp", func(a *AutoDateHistogramAgg, ib AggBuilder) {
+					a.Buckets = 20
+					a.Format = DateFormatEpochMS
+				})
+			})
+			aggBuilder.IPRange("3", "client_ip", func(a *RangeAggregation, ib AggBuilder) {
+				a.Ranges = append(a.Ranges, AggregationRange{Mask: "10.0.0.0/8"})
+			})
+
+			Convey("When marshal to JSON should generate correct json", func() {
+				sr, err := b.Build()
+				So(err, ShouldBeNil)
+				body, err := json.Marshal(sr)
+				So(err, ShouldBeNil)
+				json, err := simplejson.NewJson(body)
+				So(err, ShouldBeNil)
+
+				rangeAgg := json.GetPath("aggs", "1", "range")
+				So(rangeAgg.Get("field").MustString(), ShouldEqual, "latency")
+				So(rangeAgg.Get("keyed").MustBool(), ShouldBeTrue)
+				So(rangeAgg.Get("ranges").MustArray(), ShouldHaveLength, 2)
+				So(rangeAgg.Get("ranges").GetIndex(0).Get("to").MustInt(), ShouldEqual, 100)
+				So(rangeAgg.Get("ranges").GetIndex(0).Get("from").Interface(), ShouldBeNil)
+				So(rangeAgg.Get("ranges").GetIndex(1).Get("from").MustInt(), ShouldEqual, 100)
+
+				autoDateHistogramAgg := json.GetPath("aggs", "1", "aggs", "2", "auto_date_histogram")
+				So(autoDateHistogramAgg.Get("buckets").MustInt(), ShouldEqual, 20)
+
+				ipRangeAgg := json.GetPath("aggs", "3", "ip_range")
+				So(ipRangeAgg.Get("ranges").GetIndex(0).Get("mask").MustString(), ShouldEqual, "10.0.0.0/8")
+			})
+		})
+
 		Convey("Given new search request builder for Elasticsearch 2.0.0", func() {
 			version, _ := semver.NewVersion("2.0.0")
 
@@ -0,0 +1,141 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// sqlClauseKeywords are the clauses that can follow the WHERE clause of a SELECT statement
+var sqlClauseKeywords = []string{"GROUP BY", "HAVING", "ORDER BY", "LIMIT"}
+
+// SQLRequestBuilder represents a SQL request builder
+type SQLRequestBuilder struct {
+	index     string
+	sqlQuery  string
+	fetchSize int
+}
+
+// NewSQLRequestBuilder create a new SQL request builder
+func NewSQLRequestBuilder(index string) *SQLRequestBuilder {
+	builder := &SQLRequestBuilder{
+		index: index,
+	}
+	return builder
+}
+
+// Build builds and return a SQL query object
+func (b *SQLRequestBuilder) Build() (*SQLRequest, error) {
+	return &SQLRequest{
+		Query:     b.sqlQuery,
+		FetchSize: b.fetchSize,
+	}, nil
+}
+
+// FetchSize sets the number of rows of the first page of the response, the response has a cursor
+// to fetch the next pages when there are more rows
+func (b *SQLRequestBuilder) FetchSize(size int) *SQLRequestBuilder {
+	b.fetchSize = size
+	return b
+}
+
+// AddSQLQueryString adds a new SQL query string with the time range filter added to its WHERE clause
+func (b *SQLRequestBuilder) AddSQLQueryString(timeField, to, from, querystring string) *SQLRequestBuilder {
+	timeFilter := fmt.Sprintf("`%s` >= timestamp('%s') AND `%s` <= timestamp('%s')", timeField, from, timeField, to)
+
+	// Sets a default query if the query string is empty
+	querystring = strings.TrimSuffix(strings.TrimSpace(querystring), ";")
+	if len(querystring) == 0 {
+		querystring = fmt.Sprintf("SELECT * FROM %s", b.index)
+	}
+
+	clauses := findSQLClauses(querystring, append([]string{"WHERE"}, sqlClauseKeywords...))
+
+	// the filter of the query is the WHERE clause up to the next clause
+	end := len(querystring)
+	for _, keyword := range sqlClauseKeywords {
+		if pos, ok := clauses[keyword]; ok && pos < end {
+			end = pos
+		}
+	}
+
+	if where, ok := clauses["WHERE"]; ok && where < end {
+		condition := strings.TrimSpace(querystring[where+len("WHERE") : end])
+		b.sqlQuery = joinSQL(querystring[:where], fmt.Sprintf("WHERE %s AND (%s)", timeFilter, condition), querystring[end:])
+	} else {
+		b.sqlQuery = joinSQL(querystring[:end], "WHERE "+timeFilter, querystring[end:])
+	}
+	return b
+}
+
+// findSQLClauses returns the positions of the clause keywords of the outermost SELECT statement,
+// skipping the keywords in quotes, identifiers and parentheses
+func findSQLClauses(query string, keywords []string) map[string]int {
+	clauses := make(map[string]int)
+	depth := 0
+	var quote rune
+	prev := ' '
+
+	for i, c := range query {
+		// keywords start a word
+		wordStart := unicode.IsSpace(prev) || prev == ')'
+		prev = c
+
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+			continue
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+			continue
+		case c == '(':
+			depth++
+			continue
+		case c == ')':
+			depth--
+			continue
+		}
+
+		if depth > 0 || !wordStart {
+			continue
+		}
+		for _, keyword := range keywords {
+			if _, found := clauses[keyword]; found || !hasSQLKeyword(query[i:], keyword) {
+				continue
+			}
+			clauses[keyword] = i
+		}
+	}
+
+	return clauses
+}
+
+// hasSQLKeyword checks whether s starts with the keyword, in any case and with any spacing between its words
+func hasSQLKeyword(s, keyword string) bool {
+	for i, word := range strings.Fields(keyword) {
+		if i > 0 {
+			trimmed := strings.TrimLeftFunc(s, unicode.IsSpace)
+			if len(trimmed) == len(s) {
+				return false
+			}
+			s = trimmed
+		}
+		if len(s) < len(word) || !strings.EqualFold(s[:len(word)], word) {
+			return false
+		}
+		s = s[len(word):]
+	}
+	return len(s) == 0 || unicode.IsSpace(rune(s[0])) || s[0] == '('
+}
+
+func joinSQL(parts ...string) string {
+	res := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			res = append(res, part)
+		}
+	}
+	return strings.Join(res, " ")
+}
@@ -0,0 +1,70 @@
+package client
+
+import (
+	"encoding/json"
+	"testing"
+
+	simplejson "github.com/bitly/go-simplejson"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSQLRequest(t *testing.T) {
+	Convey("Test OpenSearch SQL request", t, func() {
+		timeField := "@timestamp"
+		index := "default_index"
+		timeFilter := "`@timestamp` >= timestamp('$timeFrom') AND `@timestamp` <= timestamp('$timeTo')"
+
+		Convey("Given new SQL request builder", func() {
+			b := NewSQLRequestBuilder(index)
+
+			Convey("When adding default query", func() {
+				b.AddSQLQueryString(timeField, "$timeTo", "$timeFrom", "")
+				sr, err := b.Build()
+				So(err, ShouldBeNil)
+
+				Convey("Should select the documents of the index in the time range", func() {
+					So(sr.Query, ShouldEqual, "SELECT * FROM default_index WHERE "+timeFilter)
+				})
+
+				Convey("When marshal to JSON should generate correct json", func() {
+					body, err := json.Marshal(sr)
+					So(err, ShouldBeNil)
+					json, err := simplejson.NewJson(body)
+					So(err, ShouldBeNil)
+					So(json.Get("query").Interface(), ShouldEqual, "SELECT * FROM default_index WHERE "+timeFilter)
+				})
+			})
+
+			Convey("When adding a query without a WHERE clause", func() {
+				b.AddSQLQueryString(timeField, "$timeTo", "$timeFrom", "SELECT host, count(*) FROM logs GROUP BY host ORDER BY host LIMIT 5;")
+				sr, err := b.Build()
+				So(err, ShouldBeNil)
+
+				Convey("Should add the time range filter before the other clauses", func() {
+					So(sr.Query, ShouldEqual, "SELECT host, count(*) FROM logs WHERE "+timeFilter+" GROUP BY host ORDER BY host LIMIT 5")
+				})
+			})
+
+			Convey("When adding a query with a WHERE clause", func() {
+				b.AddSQLQueryString(timeField, "$timeTo", "$timeFrom", "select * from logs where level = 'error' or msg = 'order by' order by @timestamp")
+				sr, err := b.Build()
+				So(err, ShouldBeNil)
+
+				Convey("Should combine the time range filter with the condition", func() {
+					So(sr.Query, ShouldEqual, "select * from logs WHERE "+timeFilter+" AND (level = 'error' or msg = 'order by') order by @timestamp")
+				})
+			})
+
+			Convey("When adding a query with a subquery", func() {
+				b.AddSQLQueryString(timeField, "$timeTo", "$timeFrom", "SELECT * FROM (SELECT * FROM logs WHERE a = 1) AS t WHERE b > 2")
+				sr, err := b.Build()
+				So(err, ShouldBeNil)
+
+				Convey("Should only filter the outer statement", func() {
+					So(sr.Query, ShouldEqual, "SELECT * FROM (SELECT * FROM logs WHERE a = 1) AS t WHERE "+timeFilter+" AND (b > 2)")
+				})
+			})
+		})
+	})
+}
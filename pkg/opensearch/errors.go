@@ -0,0 +1,39 @@
+package opensearch
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+// queryErrorResponse returns the response of an invalid query, which is not sent to the cluster
+func queryErrorResponse(err error) backend.DataResponse {
+	return backend.DataResponse{
+		Error:  err,
+		Status: backend.StatusBadRequest,
+	}
+}
+
+// downstreamErrorResponse returns the response of a query whose request to the cluster failed
+func downstreamErrorResponse(err error) backend.DataResponse {
+	status := backend.StatusBadGateway
+	if errors.Is(err, context.DeadlineExceeded) {
+		status = backend.StatusTimeout
+	}
+
+	return backend.DataResponse{
+		Error:  err,
+		Status: status,
+	}
+}
+
+// responseErrorStatus returns the status of a query the cluster answered with an error. The cluster
+// reports errors of the query itself, like a syntax error, with a 4xx status.
+func responseErrorStatus(status int) backend.Status {
+	if status >= http.StatusBadRequest && status < 600 {
+		return backend.Status(status)
+	}
+	return backend.StatusBadGateway
+}
@@ -0,0 +1,135 @@
+package opensearch
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	es "github.com/grafana/opensearch-datasource/pkg/opensearch/client"
+)
+
+// healthCheckTimeRange is the time range used to resolve the indices of the index pattern
+const healthCheckTimeRange = time.Hour
+
+func healthCheckError(format string, a ...interface{}) *backend.CheckHealthResult {
+	return &backend.CheckHealthResult{
+		Status:  backend.HealthStatusError,
+		Message: fmt.Sprintf(format, a...),
+	}
+}
+
+// checkVersion compares the flavor and version reported by the cluster with the saved settings. Only the major
+// and minor versions are compared, a patch upgrade of the cluster doesn't change what the data source sends.
+func checkVersion(client es.Client) error {
+	info, err := client.GetClusterInfo()
+	if err != nil {
+		return fmt.Errorf("failed to connect to the cluster: %w", err)
+	}
+
+	version, err := semver.NewVersion(info.Version.Number)
+	if err != nil {
+		return fmt.Errorf("the cluster reported an invalid version %q", info.Version.Number)
+	}
+
+	configured := client.GetVersion()
+	if info.GetFlavor() != client.GetFlavor() || version.Major() != configured.Major() || version.Minor() != configured.Minor() {
+		return fmt.Errorf("the data source is configured for %s %s but the cluster runs %s %s, save the data source settings again to update the version",
+			client.GetFlavor(), configured, info.GetFlavor(), version)
+	}
+
+	return nil
+}
+
+// checkTimeField verifies that the index pattern matches at least one index and that the time field
+// is mapped as a date in one of the matching indices
+func checkTimeField(client es.Client, indexPattern string) error {
+	mapping, err := client.GetMapping()
+	if err != nil {
+		return fmt.Errorf("failed to get the mapping of the index pattern %q: %w", indexPattern, err)
+	}
+
+	if len(mapping) == 0 {
+		return fmt.Errorf("no index found for the index pattern %q in the last hour, check the index name and the pattern interval", indexPattern)
+	}
+
+	timeField := client.GetTimeField()
+	for _, indexMapping := range mapping {
+		if isDateField(getFieldType(getIndexProperties(indexMapping), timeField)) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("no date field named %q found in the indices of %q, check the time field name", timeField, indexPattern)
+}
+
+// checkPPL runs a minimal PPL query to verify that the SQL plugin, which provides PPL, is installed
+func checkPPL(client es.Client) error {
+	to := time.Now().UTC()
+	from := to.Add(-healthCheckTimeRange)
+
+	builder := client.PPL()
+	builder.AddPPLQueryString(client.GetTimeField(), to.Format("2006-01-02 15:04:05"), from.Format("2006-01-02 15:04:05"), fmt.Sprintf("source = %s | head 1", client.GetIndex()))
+	req, err := builder.Build()
+	if err != nil {
+		return err
+	}
+
+	res, err := client.ExecutePPLQuery(req)
+	if err != nil {
+		return fmt.Errorf("PPL query failed: %w, disable PPL in the data source settings if the SQL plugin is not installed", err)
+	}
+	if res.Error != nil {
+		return fmt.Errorf("PPL query failed: %w, disable PPL in the data source settings if the SQL plugin is not installed", getErrorFromPPLResponse(res))
+	}
+
+	return nil
+}
+
+// getIndexProperties returns the field mappings of an index, with or without a mapping type
+func getIndexProperties(indexMapping interface{}) map[string]interface{} {
+	index, _ := indexMapping.(map[string]interface{})
+	mappings, _ := index["mappings"].(map[string]interface{})
+	if properties, ok := mappings["properties"].(map[string]interface{}); ok {
+		return properties
+	}
+
+	// before Elasticsearch 7 the fields are mapped per mapping type
+	properties := make(map[string]interface{})
+	for _, typeMapping := range mappings {
+		m, _ := typeMapping.(map[string]interface{})
+		if typeProperties, ok := m["properties"].(map[string]interface{}); ok {
+			for k, v := range typeProperties {
+				properties[k] = v
+			}
+		}
+	}
+	return properties
+}
+
+// getFieldType returns the mapped type of a field, which can be nested in object fields
+func getFieldType(properties map[string]interface{}, field string) string {
+	if fieldMapping, ok := properties[field].(map[string]interface{}); ok {
+		fieldType, _ := fieldMapping["type"].(string)
+		return fieldType
+	}
+
+	parts := strings.SplitN(field, ".", 2)
+	if len(parts) < 2 {
+		return ""
+	}
+	objectMapping, ok := properties[parts[0]].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	nestedProperties, ok := objectMapping["properties"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	return getFieldType(nestedProperties, parts[1])
+}
+
+func isDateField(fieldType string) bool {
+	return fieldType == "date" || fieldType == "date_nanos"
+}
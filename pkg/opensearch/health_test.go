@@ -0,0 +1,149 @@
+package opensearch
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	es "github.com/grafana/opensearch-datasource/pkg/opensearch/client"
+	"github.com/grafana/opensearch-datasource/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckHealth(t *testing.T) {
+	newHealthyClient := func() *fakeClient {
+		c := newFakeClient(es.OpenSearch, "2.5.0")
+		c.clusterInfo = &es.ClusterInfo{Version: es.ClusterVersion{Number: "2.5.0", Distribution: "opensearch"}}
+		c.mapping = map[string]interface{}{
+			"metrics-2023.01.01": map[string]interface{}{
+				"mappings": map[string]interface{}{
+					"properties": map[string]interface{}{
+						"@timestamp": map[string]interface{}{"type": "date"},
+					},
+				},
+			},
+		}
+		return c
+	}
+
+	t.Run("Reports a healthy data source", func(t *testing.T) {
+		res := checkHealthForTest(t, newHealthyClient(), nil)
+		assert.Equal(t, backend.HealthStatusOk, res.Status)
+		assert.Equal(t, "Index OK. Time field name OK.", res.Message)
+	})
+
+	t.Run("Reports a version mismatch", func(t *testing.T) {
+		c := newHealthyClient()
+		c.clusterInfo = &es.ClusterInfo{Version: es.ClusterVersion{Number: "7.10.2"}}
+		res := checkHealthForTest(t, c, nil)
+		assert.Equal(t, backend.HealthStatusError, res.Status)
+		assert.Equal(t, "the data source is configured for opensearch 2.5.0 but the cluster runs elasticsearch 7.10.2, save the data source settings again to update the version", res.Message)
+	})
+
+	t.Run("Reports a minor version mismatch", func(t *testing.T) {
+		c := newHealthyClient()
+		c.clusterInfo = &es.ClusterInfo{Version: es.ClusterVersion{Number: "2.6.0", Distribution: "opensearch"}}
+		res := checkHealthForTest(t, c, nil)
+		assert.Equal(t, backend.HealthStatusError, res.Status)
+		assert.Equal(t, "the data source is configured for opensearch 2.5.0 but the cluster runs opensearch 2.6.0, save the data source settings again to update the version", res.Message)
+	})
+
+	t.Run("Accepts a cluster with another patch version", func(t *testing.T) {
+		c := newHealthyClient()
+		c.clusterInfo = &es.ClusterInfo{Version: es.ClusterVersion{Number: "2.5.1", Distribution: "opensearch"}}
+		res := checkHealthForTest(t, c, nil)
+		assert.Equal(t, backend.HealthStatusOk, res.Status)
+	})
+
+	t.Run("Skips the version check for serverless", func(t *testing.T) {
+		c := newHealthyClient()
+		c.clusterInfo = &es.ClusterInfo{Version: es.ClusterVersion{Number: "7.10.2"}}
+		res := checkHealthForTest(t, c, map[string]interface{}{"serverless": true})
+		assert.Equal(t, backend.HealthStatusOk, res.Status)
+	})
+
+	t.Run("Reports a connection error", func(t *testing.T) {
+		c := newHealthyClient()
+		c.requestError = errors.New("connection refused")
+		res := checkHealthForTest(t, c, nil)
+		assert.Equal(t, backend.HealthStatusError, res.Status)
+		assert.Equal(t, "failed to connect to the cluster: connection refused", res.Message)
+	})
+
+	t.Run("Reports an index pattern without indices", func(t *testing.T) {
+		c := newHealthyClient()
+		c.mapping = map[string]interface{}{}
+		res := checkHealthForTest(t, c, nil)
+		assert.Equal(t, backend.HealthStatusError, res.Status)
+		assert.Contains(t, res.Message, "no index found")
+	})
+
+	t.Run("Reports a time field that is not a date", func(t *testing.T) {
+		c := newHealthyClient()
+		c.timeField = "timestamp"
+		res := checkHealthForTest(t, c, nil)
+		assert.Equal(t, backend.HealthStatusError, res.Status)
+		assert.Contains(t, res.Message, `no date field named "timestamp" found`)
+	})
+
+	t.Run("Finds time fields nested in objects", func(t *testing.T) {
+		c := newHealthyClient()
+		c.timeField = "event.created"
+		c.mapping = map[string]interface{}{
+			"metrics-2023.01.01": map[string]interface{}{
+				"mappings": map[string]interface{}{
+					"properties": map[string]interface{}{
+						"event": map[string]interface{}{
+							"properties": map[string]interface{}{
+								"created": map[string]interface{}{"type": "date_nanos"},
+							},
+						},
+					},
+				},
+			},
+		}
+		res := checkHealthForTest(t, c, nil)
+		assert.Equal(t, backend.HealthStatusOk, res.Status)
+	})
+
+	t.Run("Reports PPL errors", func(t *testing.T) {
+		c := newHealthyClient()
+		c.pplResponse = &es.PPLResponse{Error: map[string]interface{}{"reason": "no handler found for uri"}}
+		res := checkHealthForTest(t, c, nil)
+		assert.Equal(t, backend.HealthStatusError, res.Status)
+		assert.Equal(t, "PPL query failed: no handler found for uri, disable PPL in the data source settings if the SQL plugin is not installed", res.Message)
+
+		res = checkHealthForTest(t, c, map[string]interface{}{"pplEnabled": false})
+		assert.Equal(t, backend.HealthStatusOk, res.Status)
+	})
+}
+
+func checkHealthForTest(t *testing.T, c *fakeClient, jsonData map[string]interface{}) *backend.CheckHealthResult {
+	t.Helper()
+
+	originalNewClient := es.NewClient
+	es.NewClient = func(ctx context.Context, ds *es.DatasourceInfo, timeRange *backend.TimeRange) (es.Client, error) {
+		return c, nil
+	}
+	t.Cleanup(func() {
+		es.NewClient = originalNewClient
+	})
+
+	if jsonData == nil {
+		jsonData = map[string]interface{}{}
+	}
+	jsonData["version"] = "2.5.0"
+	jsonData["timeField"] = "@timestamp"
+	jsonData["database"] = "[metrics-]YYYY.MM.DD"
+
+	instance, err := NewOpenSearchDatasource(backend.DataSourceInstanceSettings{
+		JSONData: utils.NewRawJsonFromAny(jsonData),
+	})
+	require.NoError(t, err)
+
+	res, err := instance.(*OpenSearchDatasource).CheckHealth(context.Background(), &backend.CheckHealthRequest{})
+	assert.NoError(t, err)
+	return res
+}
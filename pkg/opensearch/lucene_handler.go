@@ -1,11 +1,20 @@
 package opensearch
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/Masterminds/semver"
+	"github.com/bitly/go-simplejson"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	es "github.com/grafana/opensearch-datasource/pkg/opensearch/client"
 	"github.com/grafana/opensearch-datasource/pkg/tsdb"
 	"github.com/grafana/opensearch-datasource/pkg/utils"
@@ -41,6 +50,60 @@ func (h *luceneHandler) processQuery(q *Query) error {
 	}
 	interval := h.intervalCalculator.Calculate(&h.req.Queries[0].TimeRange, minInterval)
 
+	calendarIntervals := supportsCalendarIntervals(h.client.GetFlavor(), h.client.GetVersion())
+	for _, bucketAgg := range q.BucketAggs {
+		switch bucketAgg.Type {
+		case dateHistType:
+			if _, err := getDateHistogramInterval(bucketAgg, interval, calendarIntervals); err != nil {
+				return err
+			}
+			if _, err := getDateHistogramTimeZone(bucketAgg, q.TimeZone); err != nil {
+				return err
+			}
+		case autoDateHistType, dateRangeType:
+			if _, err := getDateHistogramTimeZone(bucketAgg, q.TimeZone); err != nil {
+				return err
+			}
+		}
+
+		if isRangeAgg(bucketAgg.Type) && len(bucketAgg.Settings.Get("ranges").MustArray()) == 0 {
+			return fmt.Errorf("the %s aggregation %s needs at least one range", bucketAgg.Type, bucketAgg.ID)
+		}
+		// the cluster only pages through composite aggregations that aren't nested in other buckets
+		if bucketAgg.Type == compositeType {
+			if bucketAgg != q.BucketAggs[0] {
+				return fmt.Errorf("the composite aggregation %s should be the first bucket aggregation", bucketAgg.ID)
+			}
+			if bucketAgg.Settings.Get("size").MustInt(defaultCompositePageSize) <= 0 || bucketAgg.Settings.Get("maxBuckets").MustInt(defaultCompositeMaxBuckets) <= 0 {
+				return fmt.Errorf("the composite aggregation %s needs a page size and a bucket limit greater than 0", bucketAgg.ID)
+			}
+			// the cluster computes the sibling pipelines over the buckets of the first page only
+			if bucketAgg == q.BucketAggs[len(q.BucketAggs)-1] {
+				for _, m := range q.Metrics {
+					if isSiblingPipelineAgg(m.Type) {
+						return fmt.Errorf("the %s metric %s can't aggregate the buckets of the composite aggregation %s, which are fetched in pages", m.Type, m.ID, bucketAgg.ID)
+					}
+				}
+			}
+		}
+		if bucketAgg.Type == multiTermsType && len(getKeyFields(bucketAgg)) < 2 {
+			return fmt.Errorf("the multi_terms aggregation %s needs at least two fields", bucketAgg.ID)
+		}
+	}
+
+	for _, m := range q.Metrics {
+		if err := validateMetricAgg(m); err != nil {
+			return err
+		}
+	}
+
+	var rawDSL map[string]interface{}
+	if q.LuceneQueryType == luceneQueryTypeRawDSL {
+		if rawDSL, err = parseRawDSLQuery(q.RawQuery); err != nil {
+			return err
+		}
+	}
+
 	h.queries = append(h.queries, q)
 
 	b := h.ms.Search(interval)
@@ -48,28 +111,36 @@ func (h *luceneHandler) processQuery(q *Query) error {
 	filters := b.Query().Bool().Filter()
 	filters.AddDateRangeFilter(h.client.GetTimeField(), to, from, es.DateFormatEpochMS)
 
-	if q.RawQuery != "" {
+	if rawDSL != nil {
+		addRawDSLQuery(b, rawDSL)
+	} else if q.RawQuery != "" {
 		filters.AddQueryStringFilter(q.RawQuery, true)
 	}
+	filters.AddAdHocFilters(q.AdHocFilters)
+
+	if q.IsLogsQuery {
+		processLogsQuery(q, b, h.client.GetTimeField())
+		return nil
+	}
 
 	if len(q.BucketAggs) == 0 {
-		if len(q.Metrics) == 0 || q.Metrics[0].Type != "raw_document" {
-			return nil
+		if isDocumentQuery(q) {
+			processDocumentQuery(q, b, h.client.GetTimeField())
 		}
-		metric := q.Metrics[0]
-		b.Size(metric.Settings.Get("size").MustInt(500))
-		b.SortDesc("@timestamp", "boolean")
-		b.AddDocValueField("@timestamp")
 		return nil
 	}
 
 	aggBuilder := b.Agg()
+	// sibling pipeline aggregations are added next to the last bucket aggregation
+	siblingAggBuilder := aggBuilder
+	lastBucketAgg := q.BucketAggs[len(q.BucketAggs)-1]
 
 	// iterate backwards to create aggregations bottom-down
 	for _, bucketAgg := range q.BucketAggs {
+		siblingAggBuilder = aggBuilder
 		switch bucketAgg.Type {
 		case dateHistType:
-			aggBuilder = addDateHistogramAgg(aggBuilder, bucketAgg, from, to)
+			aggBuilder = addDateHistogramAgg(aggBuilder, bucketAgg, from, to, interval, calendarIntervals, q.TimeZone)
 		case histogramType:
 			aggBuilder = addHistogramAgg(aggBuilder, bucketAgg)
 		case filtersType:
@@ -78,6 +149,22 @@ func (h *luceneHandler) processQuery(q *Query) error {
 			aggBuilder = addTermsAgg(aggBuilder, bucketAgg, q.Metrics)
 		case geohashGridType:
 			aggBuilder = addGeoHashGridAgg(aggBuilder, bucketAgg)
+		case geotileGridType:
+			aggBuilder = addGeoTileGridAgg(aggBuilder, bucketAgg)
+		case geohexGridType:
+			aggBuilder = addGeoHexGridAgg(aggBuilder, bucketAgg)
+		case autoDateHistType:
+			aggBuilder = addAutoDateHistogramAgg(aggBuilder, bucketAgg, q.TimeZone)
+		case rangeType, dateRangeType, ipRangeType:
+			aggBuilder = addRangeAgg(aggBuilder, bucketAgg, q.TimeZone)
+		case compositeType:
+			aggBuilder = addCompositeAgg(aggBuilder, bucketAgg)
+		case multiTermsType:
+			aggBuilder = addMultiTermsAgg(aggBuilder, bucketAgg, q.Metrics)
+		case rareTermsType:
+			aggBuilder = addRareTermsAgg(aggBuilder, bucketAgg)
+		case significantTermsType:
+			aggBuilder = addSignificantTermsAgg(aggBuilder, bucketAgg)
 		}
 	}
 
@@ -88,7 +175,15 @@ func (h *luceneHandler) processQuery(q *Query) error {
 		}
 
 		if isPipelineAgg(m.Type) {
-			if isPipelineAggWithMultipleBucketPaths(m.Type) {
+			if m.Type == bucketSortType {
+				addBucketSortAgg(aggBuilder, m, q.Metrics)
+			} else if isSiblingPipelineAgg(m.Type) {
+				if bucketPath, ok := getPipelineBucketPath(m.PipelineAggregate, q.Metrics); ok {
+					siblingAggBuilder.Pipeline(m.ID, m.Type, lastBucketAgg.ID+">"+bucketPath, func(a *es.PipelineAggregation) {
+						a.Settings = m.Settings.MustMap()
+					})
+				}
+			} else if isPipelineAggWithMultipleBucketPaths(m.Type) {
 				if len(m.PipelineVariables) > 0 {
 					bucketPaths := map[string]interface{}{}
 					for name, pipelineAgg := range m.PipelineVariables {
@@ -140,9 +235,7 @@ func (h *luceneHandler) processQuery(q *Query) error {
 				}
 			}
 		} else {
-			aggBuilder.Metric(m.ID, m.Type, m.Field, func(a *es.MetricAggregation) {
-				a.Settings = m.Settings.MustMap()
-			})
+			addMetricAgg(aggBuilder, m)
 		}
 	}
 
@@ -163,22 +256,221 @@ func (h *luceneHandler) executeQueries() (*backend.QueryDataResponse, error) {
 	if err != nil {
 		return nil, err
 	}
+	if res.Status >= http.StatusBadRequest {
+		return nil, fmt.Errorf("multisearch request failed with status %d", res.Status)
+	}
+
+	truncated, failed := fetchCompositePages(h.client, req, res)
+
+	rp := newResponseParser(res.Responses, h.queries, res.DebugInfo, newConfiguredFields(h.req.PluginContext.DataSourceInstanceSettings, h.client.GetTimeField()))
+	result, err := rp.getTimeSeries()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, err := range failed {
+		result.Responses[h.queries[i].RefID] = downstreamErrorResponse(err)
+	}
+	for i, maxBuckets := range truncated {
+		queryRes := result.Responses[h.queries[i].RefID]
+		addBucketLimitNotice(&queryRes, maxBuckets)
+		result.Responses[h.queries[i].RefID] = queryRes
+	}
+
+	return result, nil
+}
+
+// compositePage is a page of the composite aggregation of a search request
+type compositePage struct {
+	request int
+	key     string
+	agg     *es.CompositeAggregation
+	// the number of buckets and the after key of the page
+	size  int
+	after map[string]interface{}
+}
+
+// fetchCompositePages pages through the composite aggregations of the search requests with the after_key
+// of their responses, and merges the buckets of the pages into the responses, until the buckets are
+// exhausted or the bucket limit of the aggregation is reached. It returns the bucket limit of the requests
+// whose buckets were truncated, and the error of the requests whose pages couldn't be fetched.
+func fetchCompositePages(client es.Client, req *es.MultiSearchRequest, res *es.MultiSearchResponse) (map[int]int, map[int]error) {
+	truncated := make(map[int]int)
+	failed := make(map[int]error)
+	pages := make([]compositePage, 0)
+	for i, r := range req.Requests {
+		if i >= len(res.Responses) || len(r.Aggs) == 0 || r.Aggs[0].Aggregation.Type != compositeType {
+			continue
+		}
+		agg := r.Aggs[0].Aggregation.Aggregation.(*es.CompositeAggregation)
+		page := compositePage{request: i, key: r.Aggs[0].Key, agg: agg, size: agg.Size}
+		if next, ok := nextCompositePage(page, getCompositeBucketCount(res.Responses[i], page.key), res.Responses[i], truncated); ok {
+			pages = append(pages, next)
+		}
+	}
+
+	for len(pages) > 0 {
+		pageReq := &es.MultiSearchRequest{}
+		for _, page := range pages {
+			pageReq.Requests = append(pageReq.Requests, newCompositePageRequest(req.Requests[page.request], page))
+		}
+
+		pageRes, err := client.ExecuteMultisearch(pageReq)
+		if err == nil && pageRes.Status >= http.StatusBadRequest {
+			err = fmt.Errorf("multisearch request failed with status %d", pageRes.Status)
+		}
+		if err != nil {
+			// only the requests of the pages fail, the other requests keep their responses
+			for _, page := range pages {
+				failed[page.request] = err
+			}
+			break
+		}
+
+		nextPages := make([]compositePage, 0, len(pages))
+		for j, page := range pages {
+			if j >= len(pageRes.Responses) {
+				break
+			}
+			if pageRes.Responses[j].Error != nil {
+				res.Responses[page.request] = pageRes.Responses[j]
+				continue
+			}
+
+			aggs := res.Responses[page.request].Aggregations
+			composite, _ := aggs[page.key].(map[string]interface{})
+			pageComposite, _ := pageRes.Responses[j].Aggregations[page.key].(map[string]interface{})
+			buckets, _ := composite["buckets"].([]interface{})
+			pageBuckets, _ := pageComposite["buckets"].([]interface{})
+			composite["buckets"] = append(buckets, pageBuckets...)
+			composite["after_key"] = pageComposite["after_key"]
+
+			if next, ok := nextCompositePage(page, len(pageBuckets), res.Responses[page.request], truncated); ok {
+				nextPages = append(nextPages, next)
+			}
+		}
+		pages = nextPages
+	}
 
-	rp := newResponseParser(res.Responses, h.queries, res.DebugInfo)
-	return rp.getTimeSeries()
+	return truncated, failed
 }
 
-func addDateHistogramAgg(aggBuilder es.AggBuilder, bucketAgg *BucketAgg, timeFrom, timeTo string) es.AggBuilder {
+// nextCompositePage returns the page following a page of a composite aggregation with pageBuckets buckets,
+// if the page was full and the merged buckets of the response are within the bucket limit. The page after
+// the limit is requested with a single bucket, which tells whether buckets are left. The buckets over the
+// limit are dropped.
+func nextCompositePage(page compositePage, pageBuckets int, res *es.SearchResponse, truncated map[int]int) (compositePage, bool) {
+	if res.Error != nil {
+		return page, false
+	}
+	composite, ok := res.Aggregations[page.key].(map[string]interface{})
+	if !ok {
+		return page, false
+	}
+	buckets, _ := composite["buckets"].([]interface{})
+	afterKey, _ := composite["after_key"].(map[string]interface{})
+
+	if len(buckets) > page.agg.MaxBuckets {
+		truncated[page.request] = page.agg.MaxBuckets
+		composite["buckets"] = buckets[:page.agg.MaxBuckets]
+		return page, false
+	}
+	// a page with less buckets than its size is the last one
+	if afterKey == nil || pageBuckets < page.size {
+		return page, false
+	}
+
+	next := page
+	next.after = afterKey
+	next.size = page.agg.Size
+	if remaining := page.agg.MaxBuckets - len(buckets) + 1; remaining < next.size {
+		next.size = remaining
+	}
+	return next, true
+}
+
+// getCompositeBucketCount returns the number of buckets of the composite aggregation of a response
+func getCompositeBucketCount(res *es.SearchResponse, key string) int {
+	composite, _ := res.Aggregations[key].(map[string]interface{})
+	buckets, _ := composite["buckets"].([]interface{})
+	return len(buckets)
+}
+
+// newCompositePageRequest returns a copy of the search request of a composite aggregation that requests
+// a page of the aggregation, the search request of the first page is left as is
+func newCompositePageRequest(r *es.SearchRequest, page compositePage) *es.SearchRequest {
+	composite := *page.agg
+	composite.Size = page.size
+	composite.After = page.after
+
+	container := *r.Aggs[0].Aggregation
+	container.Aggregation = &composite
+
+	pageReq := *r
+	pageReq.Aggs = append(es.AggArray{{Key: r.Aggs[0].Key, Aggregation: &container}}, r.Aggs[1:]...)
+	return &pageReq
+}
+
+func addBucketLimitNotice(res *backend.DataResponse, maxBuckets int) {
+	for _, frame := range res.Frames {
+		if frame.Meta == nil {
+			frame.Meta = &data.FrameMeta{}
+		}
+		frame.Meta.Notices = append(frame.Meta.Notices, data.Notice{
+			Severity: data.NoticeSeverityWarning,
+			Text:     fmt.Sprintf("Showing the first %d buckets, increase the bucket limit of the composite aggregation to show more", maxBuckets),
+		})
+	}
+}
+
+func processLogsQuery(q *Query, b *es.SearchRequestBuilder, timeField string) {
+	size := defaultDocumentSize
+	if len(q.Metrics) > 0 {
+		size = getSizeSetting(q.Metrics[0].Settings, defaultDocumentSize)
+	}
+
+	b.Size(size)
+	b.SortDesc(timeField, "boolean")
+	b.AddDocValueField(timeField)
+	b.AddHighlight()
+}
+
+// processDocumentQuery requests the documents of a raw_data or raw_document query sorted by the time field
+func processDocumentQuery(q *Query, b *es.SearchRequestBuilder, timeField string) {
+	metric := q.Metrics[0]
+	order := metric.Settings.Get("order").MustString("desc")
+	if order != "asc" {
+		order = "desc"
+	}
+
+	b.Size(getSizeSetting(metric.Settings, defaultDocumentSize))
+	b.Sort(order, timeField, "boolean")
+	b.AddDocValueField(timeField)
+}
+
+// getSizeSetting reads the size setting, which the query editor stores as a string
+func getSizeSetting(settings *simplejson.Json, defaultSize int) int {
+	if size, err := settings.Get("size").Int(); err == nil && size > 0 {
+		return size
+	}
+	if size, err := strconv.Atoi(settings.Get("size").MustString()); err == nil && size > 0 {
+		return size
+	}
+	return defaultSize
+}
+
+func addDateHistogramAgg(aggBuilder es.AggBuilder, bucketAgg *BucketAgg, timeFrom, timeTo string, interval tsdb.Interval, calendarIntervals bool, timeZone string) es.AggBuilder {
 	aggBuilder.DateHistogram(bucketAgg.ID, bucketAgg.Field, func(a *es.DateHistogramAgg, b es.AggBuilder) {
-		a.Interval = bucketAgg.Settings.Get("interval").MustString("auto")
+		// the interval and the time zone are validated when the query is processed
+		histogramInterval, _ := getDateHistogramInterval(bucketAgg, interval, calendarIntervals)
+		a.Interval = histogramInterval.interval
+		a.FixedInterval = histogramInterval.fixedInterval
+		a.CalendarInterval = histogramInterval.calendarInterval
+		a.TimeZone, _ = getDateHistogramTimeZone(bucketAgg, timeZone)
 		a.MinDocCount = bucketAgg.Settings.Get("min_doc_count").MustInt(0)
 		a.ExtendedBounds = &es.ExtendedBounds{Min: timeFrom, Max: timeTo}
 		a.Format = bucketAgg.Settings.Get("format").MustString(es.DateFormatEpochMS)
 
-		if a.Interval == "auto" {
-			a.Interval = "$__interval"
-		}
-
 		if offset, err := bucketAgg.Settings.Get("offset").String(); err == nil {
 			a.Offset = offset
 		}
@@ -193,6 +485,88 @@ func addDateHistogramAgg(aggBuilder es.AggBuilder, bucketAgg *BucketAgg, timeFro
 	return aggBuilder
 }
 
+var timeZoneOffsetPattern = regexp.MustCompile(`^[+-]\d{2}:\d{2}$`)
+
+// getDateHistogramTimeZone returns the time zone the buckets of a date histogram are rounded in, which
+// is also the time zone of auto date histograms and of the dates of date ranges. The time zone setting of
+// the aggregation takes precedence over the time zone of the query, and UTC and the browser time zone,
+// which the backend can't know, are left to the default of the cluster.
+func getDateHistogramTimeZone(bucketAgg *BucketAgg, queryTimeZone string) (string, error) {
+	timeZone := bucketAgg.Settings.Get("timeZone").MustString(queryTimeZone)
+	switch strings.ToLower(timeZone) {
+	case "", "utc", "browser":
+		return "", nil
+	}
+
+	if timeZoneOffsetPattern.MatchString(timeZone) {
+		return timeZone, nil
+	}
+	if _, err := time.LoadLocation(timeZone); err != nil {
+		return "", fmt.Errorf("invalid time zone %q", timeZone)
+	}
+
+	return timeZone, nil
+}
+
+// calendarIntervalsVersion is the Elasticsearch version that replaces the interval of date histograms
+// with fixed_interval and calendar_interval, OpenSearch supports both since its first version
+var calendarIntervalsVersion = semver.MustParse("7.2.0")
+
+var dateHistogramIntervalPattern = regexp.MustCompile(`^(\d+)(ms|s|m|h|d|w|M|q|y)$`)
+
+type dateHistogramInterval struct {
+	interval         string
+	fixedInterval    string
+	calendarInterval string
+}
+
+func supportsCalendarIntervals(flavor es.Flavor, version *semver.Version) bool {
+	return flavor == es.OpenSearch || (version != nil && !version.LessThan(calendarIntervalsVersion))
+}
+
+// getDateHistogramInterval returns the interval of a date histogram. Days, weeks, months, quarters and
+// years are calendar intervals, whose buckets start at the start of the unit in the time zone and
+// whose length varies with DST and the length of the months, the other units are fixed intervals.
+// Calendar intervals only support a single unit, multiple days or weeks are fixed intervals of days.
+// Intervals that aren't a duration, like a variable, are sent as the deprecated interval.
+func getDateHistogramInterval(bucketAgg *BucketAgg, interval tsdb.Interval, calendarIntervals bool) (dateHistogramInterval, error) {
+	setting := bucketAgg.Settings.Get("interval").MustString("auto")
+	if !calendarIntervals {
+		if setting == "auto" {
+			setting = "$__interval"
+		}
+		return dateHistogramInterval{interval: setting}, nil
+	}
+
+	if setting == "auto" {
+		// the auto interval can be a number of years, which isn't a fixed interval
+		return dateHistogramInterval{fixedInterval: fmt.Sprintf("%dms", interval.Value.Milliseconds())}, nil
+	}
+
+	match := dateHistogramIntervalPattern.FindStringSubmatch(setting)
+	if match == nil {
+		return dateHistogramInterval{interval: setting}, nil
+	}
+	value, err := strconv.Atoi(match[1])
+	if err != nil {
+		return dateHistogramInterval{}, fmt.Errorf("invalid date histogram interval %q", setting)
+	}
+
+	unit := match[2]
+	switch {
+	case unit == "ms" || unit == "s" || unit == "m" || unit == "h":
+		return dateHistogramInterval{fixedInterval: setting}, nil
+	case value == 1:
+		return dateHistogramInterval{calendarInterval: setting}, nil
+	case unit == "d":
+		return dateHistogramInterval{fixedInterval: setting}, nil
+	case unit == "w":
+		return dateHistogramInterval{fixedInterval: fmt.Sprintf("%dd", value*7)}, nil
+	default:
+		return dateHistogramInterval{}, fmt.Errorf("invalid date histogram interval %q, intervals of months, quarters and years should be a single unit, e.g. 1%s", setting, unit)
+	}
+}
+
 func addHistogramAgg(aggBuilder es.AggBuilder, bucketAgg *BucketAgg) es.AggBuilder {
 	aggBuilder.Histogram(bucketAgg.ID, bucketAgg.Field, func(a *es.HistogramAgg, b es.AggBuilder) {
 		a.Interval = bucketAgg.Settings.Get("interval").MustInt(1000)
@@ -208,40 +582,229 @@ func addHistogramAgg(aggBuilder es.AggBuilder, bucketAgg *BucketAgg) es.AggBuild
 	return aggBuilder
 }
 
+func addAutoDateHistogramAgg(aggBuilder es.AggBuilder, bucketAgg *BucketAgg, timeZone string) es.AggBuilder {
+	aggBuilder.AutoDateHistogram(bucketAgg.ID, bucketAgg.Field, func(a *es.AutoDateHistogramAgg, b es.AggBuilder) {
+		a.Buckets = bucketAgg.Settings.Get("buckets").MustInt(defaultAutoDateHistogramBuckets)
+		a.MinimumInterval = bucketAgg.Settings.Get("minimum_interval").MustString()
+		a.Format = bucketAgg.Settings.Get("format").MustString(es.DateFormatEpochMS)
+		// the time zone is validated when the query is processed
+		a.TimeZone, _ = getDateHistogramTimeZone(bucketAgg, timeZone)
+
+		if missing, err := bucketAgg.Settings.Get("missing").String(); err == nil {
+			a.Missing = &missing
+		}
+
+		aggBuilder = b
+	})
+
+	return aggBuilder
+}
+
+// addRangeAgg adds a range, date_range or ip_range aggregation. The bounds of the ranges are passed as
+// they are, numbers for range, dates or date math for date_range and addresses for ip_range.
+func addRangeAgg(aggBuilder es.AggBuilder, bucketAgg *BucketAgg, timeZone string) es.AggBuilder {
+	fn := func(a *es.RangeAggregation, b es.AggBuilder) {
+		for _, r := range bucketAgg.Settings.Get("ranges").MustArray() {
+			rangeJSON := utils.NewJsonFromAny(r)
+			a.Ranges = append(a.Ranges, es.AggregationRange{
+				Key:  rangeJSON.Get("key").MustString(),
+				From: getRangeBound(rangeJSON.Get("from")),
+				To:   getRangeBound(rangeJSON.Get("to")),
+				Mask: rangeJSON.Get("mask").MustString(),
+			})
+		}
+		a.Keyed = bucketAgg.Settings.Get("keyed").MustBool(false)
+		a.Format = bucketAgg.Settings.Get("format").MustString()
+		if bucketAgg.Type == dateRangeType {
+			a.TimeZone, _ = getDateHistogramTimeZone(bucketAgg, timeZone)
+		}
+
+		aggBuilder = b
+	}
+
+	switch bucketAgg.Type {
+	case dateRangeType:
+		aggBuilder.DateRange(bucketAgg.ID, bucketAgg.Field, fn)
+	case ipRangeType:
+		aggBuilder.IPRange(bucketAgg.ID, bucketAgg.Field, fn)
+	default:
+		aggBuilder.Range(bucketAgg.ID, bucketAgg.Field, fn)
+	}
+
+	return aggBuilder
+}
+
+// addCompositeAgg adds a composite aggregation with a terms source per field, named after the field
+func addCompositeAgg(aggBuilder es.AggBuilder, bucketAgg *BucketAgg) es.AggBuilder {
+	aggBuilder.Composite(bucketAgg.ID, func(a *es.CompositeAggregation, b es.AggBuilder) {
+		a.Size = bucketAgg.Settings.Get("size").MustInt(defaultCompositePageSize)
+		a.MaxBuckets = bucketAgg.Settings.Get("maxBuckets").MustInt(defaultCompositeMaxBuckets)
+		for _, field := range getKeyFields(bucketAgg) {
+			a.Sources = append(a.Sources, es.CompositeSource{Name: field, Type: termsType, Field: field})
+		}
+
+		aggBuilder = b
+	})
+
+	return aggBuilder
+}
+
+// getRangeBound returns the bound of a range, an empty bound leaves the range open
+func getRangeBound(bound *simplejson.Json) interface{} {
+	if s, err := bound.String(); err == nil && strings.TrimSpace(s) == "" {
+		return nil
+	}
+	return bound.Interface()
+}
+
+// getPipelineBucketPath returns the bucket path of the metric with the given id, which is _count for
+// count metrics
+func getPipelineBucketPath(metricID string, metrics []*MetricAgg) (string, bool) {
+	for _, m := range metrics {
+		if m.ID == metricID {
+			if m.Type == countType {
+				return "_count", true
+			}
+			return m.ID, true
+		}
+	}
+	return "", false
+}
+
+// addBucketSortAgg adds a bucket_sort aggregation, which sorts the buckets of its parent by metrics
+// of the query or by the _key and _count of the buckets, and truncates them to from and size
+func addBucketSortAgg(aggBuilder es.AggBuilder, m *MetricAgg, metrics []*MetricAgg) {
+	sort := make([]interface{}, 0)
+	for _, s := range m.Settings.Get("sort").MustArray() {
+		sortJSON := utils.NewJsonFromAny(s)
+		field := sortJSON.Get("field").MustString()
+		if !strings.HasPrefix(field, "_") {
+			bucketPath, ok := getPipelineBucketPath(field, metrics)
+			if !ok {
+				continue
+			}
+			field = bucketPath
+		}
+		sort = append(sort, map[string]interface{}{
+			field: map[string]interface{}{"order": sortJSON.Get("order").MustString("desc")},
+		})
+	}
+
+	aggBuilder.Pipeline(m.ID, m.Type, nil, func(a *es.PipelineAggregation) {
+		a.Settings = map[string]interface{}{}
+		if len(sort) > 0 {
+			a.Settings["sort"] = sort
+		}
+		if size, err := m.Settings.Get("size").Int(); err == nil {
+			a.Settings["size"] = size
+		}
+		if from, err := m.Settings.Get("from").Int(); err == nil {
+			a.Settings["from"] = from
+		}
+	})
+}
+
+// addMetricAgg adds a metric aggregation with the settings of the metric, the metrics that don't
+// aggregate a single field are built from their settings instead
+func addMetricAgg(aggBuilder es.AggBuilder, m *MetricAgg) {
+	aggBuilder.Metric(m.ID, m.Type, m.Field, func(a *es.MetricAggregation) {
+		switch m.Type {
+		case weightedAvgType:
+			a.Field = ""
+			a.Settings = map[string]interface{}{
+				"value":  map[string]interface{}{"field": m.Field},
+				"weight": map[string]interface{}{"field": m.Settings.Get("weight").MustString()},
+			}
+		case topMetricsType:
+			metrics := make([]interface{}, 0)
+			for _, field := range m.Settings.Get("metrics").MustStringArray() {
+				metrics = append(metrics, map[string]interface{}{"field": field})
+			}
+
+			a.Field = ""
+			a.Settings = map[string]interface{}{
+				"metrics": metrics,
+				"sort": map[string]interface{}{
+					m.Settings.Get("orderBy").MustString(): m.Settings.Get("order").MustString("desc"),
+				},
+				"size": 1,
+			}
+		default:
+			a.Settings = m.Settings.MustMap()
+		}
+	})
+}
+
+// validateMetricAgg returns an error for the metrics that are missing the settings they are built from
+func validateMetricAgg(m *MetricAgg) error {
+	switch m.Type {
+	case weightedAvgType:
+		if m.Field == "" || m.Settings.Get("weight").MustString() == "" {
+			return fmt.Errorf("the weighted average metric %s needs a value field and a weight field", m.ID)
+		}
+	case topMetricsType:
+		if len(m.Settings.Get("metrics").MustStringArray()) == 0 || m.Settings.Get("orderBy").MustString() == "" {
+			return fmt.Errorf("the top metrics metric %s needs the metrics to return and a field to order by", m.ID)
+		}
+	}
+	return nil
+}
+
 func addTermsAgg(aggBuilder es.AggBuilder, bucketAgg *BucketAgg, metrics []*MetricAgg) es.AggBuilder {
 	aggBuilder.Terms(bucketAgg.ID, bucketAgg.Field, func(a *es.TermsAggregation, b es.AggBuilder) {
-		if size, err := bucketAgg.Settings.Get("size").Int(); err == nil {
-			a.Size = size
-		} else if size, err := bucketAgg.Settings.Get("size").String(); err == nil {
-			a.Size, err = strconv.Atoi(size)
-			if err != nil {
-				a.Size = 500
-			}
-		} else {
-			a.Size = 500
+		a.Size = getTermsSize(bucketAgg)
+
+		if minDocCount, err := bucketAgg.Settings.Get("min_doc_count").Int(); err == nil {
+			a.MinDocCount = &minDocCount
 		}
-		if a.Size == 0 {
-			a.Size = 500
+		if missing, err := bucketAgg.Settings.Get("missing").String(); err == nil {
+			a.Missing = &missing
 		}
 
+		addTermsOrder(a.Order, b, bucketAgg, metrics)
+
+		aggBuilder = b
+	})
+
+	return aggBuilder
+}
+
+func addMultiTermsAgg(aggBuilder es.AggBuilder, bucketAgg *BucketAgg, metrics []*MetricAgg) es.AggBuilder {
+	aggBuilder.MultiTerms(bucketAgg.ID, getKeyFields(bucketAgg), func(a *es.MultiTermsAggregation, b es.AggBuilder) {
+		a.Size = getTermsSize(bucketAgg)
+
 		if minDocCount, err := bucketAgg.Settings.Get("min_doc_count").Int(); err == nil {
 			a.MinDocCount = &minDocCount
 		}
+
+		addTermsOrder(a.Order, b, bucketAgg, metrics)
+
+		aggBuilder = b
+	})
+
+	return aggBuilder
+}
+
+func addRareTermsAgg(aggBuilder es.AggBuilder, bucketAgg *BucketAgg) es.AggBuilder {
+	aggBuilder.RareTerms(bucketAgg.ID, bucketAgg.Field, func(a *es.RareTermsAggregation, b es.AggBuilder) {
+		a.MaxDocCount = bucketAgg.Settings.Get("max_doc_count").MustInt(1)
+
 		if missing, err := bucketAgg.Settings.Get("missing").String(); err == nil {
 			a.Missing = &missing
 		}
 
-		if orderBy, err := bucketAgg.Settings.Get("orderBy").String(); err == nil {
-			a.Order[orderBy] = bucketAgg.Settings.Get("order").MustString("desc")
+		aggBuilder = b
+	})
 
-			if _, err := strconv.Atoi(orderBy); err == nil {
-				for _, m := range metrics {
-					if m.ID == orderBy {
-						b.Metric(m.ID, m.Type, m.Field, nil)
-						break
-					}
-				}
-			}
+	return aggBuilder
+}
+
+func addSignificantTermsAgg(aggBuilder es.AggBuilder, bucketAgg *BucketAgg) es.AggBuilder {
+	aggBuilder.SignificantTerms(bucketAgg.ID, bucketAgg.Field, func(a *es.SignificantTermsAggregation, b es.AggBuilder) {
+		a.Size = bucketAgg.Settings.Get("size").MustInt(0)
+
+		if minDocCount, err := bucketAgg.Settings.Get("min_doc_count").Int(); err == nil {
+			a.MinDocCount = &minDocCount
 		}
 
 		aggBuilder = b
@@ -250,6 +813,41 @@ func addTermsAgg(aggBuilder es.AggBuilder, bucketAgg *BucketAgg, metrics []*Metr
 	return aggBuilder
 }
 
+// getTermsSize returns the number of buckets of a terms or multi_terms aggregation, which is 500 when it
+// isn't set
+func getTermsSize(bucketAgg *BucketAgg) int {
+	size, err := bucketAgg.Settings.Get("size").Int()
+	if err != nil {
+		if size, err = strconv.Atoi(bucketAgg.Settings.Get("size").MustString()); err != nil {
+			return 500
+		}
+	}
+	if size == 0 {
+		return 500
+	}
+	return size
+}
+
+// addTermsOrder sets the order of a terms or multi_terms aggregation, the metric the buckets are ordered
+// by is added to the buckets
+func addTermsOrder(order map[string]interface{}, b es.AggBuilder, bucketAgg *BucketAgg, metrics []*MetricAgg) {
+	orderBy, err := bucketAgg.Settings.Get("orderBy").String()
+	if err != nil {
+		return
+	}
+
+	order[orderBy] = bucketAgg.Settings.Get("order").MustString("desc")
+
+	if _, err := strconv.Atoi(orderBy); err == nil {
+		for _, m := range metrics {
+			if m.ID == orderBy {
+				b.Metric(m.ID, m.Type, m.Field, nil)
+				break
+			}
+		}
+	}
+}
+
 func addFiltersAgg(aggBuilder es.AggBuilder, bucketAgg *BucketAgg) es.AggBuilder {
 	filters := make(map[string]interface{})
 	for _, filter := range bucketAgg.Settings.Get("filters").MustArray() {
@@ -280,3 +878,71 @@ func addGeoHashGridAgg(aggBuilder es.AggBuilder, bucketAgg *BucketAgg) es.AggBui
 
 	return aggBuilder
 }
+
+func addGeoTileGridAgg(aggBuilder es.AggBuilder, bucketAgg *BucketAgg) es.AggBuilder {
+	aggBuilder.GeoTileGrid(bucketAgg.ID, bucketAgg.Field, func(a *es.GeoTileGridAggregation, b es.AggBuilder) {
+		a.Precision = bucketAgg.Settings.Get("precision").MustInt(a.Precision)
+		aggBuilder = b
+	})
+
+	return aggBuilder
+}
+
+func addGeoHexGridAgg(aggBuilder es.AggBuilder, bucketAgg *BucketAgg) es.AggBuilder {
+	aggBuilder.GeoHexGrid(bucketAgg.ID, bucketAgg.Field, func(a *es.GeoHexGridAggregation, b es.AggBuilder) {
+		a.Precision = bucketAgg.Settings.Get("precision").MustInt(a.Precision)
+		aggBuilder = b
+	})
+
+	return aggBuilder
+}
+
+// parseRawDSLQuery reads the search request body of a raw DSL query. The aggregations of the request
+// are built from the query's metrics and bucket aggregations, so that the response parser can read them.
+func parseRawDSLQuery(rawQuery string) (map[string]interface{}, error) {
+	body := make(map[string]interface{})
+	if strings.TrimSpace(rawQuery) == "" {
+		return body, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader([]byte(rawQuery)))
+	dec.UseNumber()
+	if err := dec.Decode(&body); err != nil {
+		return nil, fmt.Errorf("invalid raw DSL query: %w", err)
+	}
+	if body == nil {
+		return nil, errors.New("invalid raw DSL query: the query should be a JSON object")
+	}
+	for _, key := range []string{"aggs", "aggregations"} {
+		if _, ok := body[key]; ok {
+			return nil, fmt.Errorf("invalid raw DSL query: %s are defined by the metrics and bucket aggregations of the query", key)
+		}
+	}
+	// the size and the sort are set from the metric of the query, the logs and raw data limits included
+	for _, key := range []string{"size", "sort"} {
+		if _, ok := body[key]; ok {
+			return nil, fmt.Errorf("invalid raw DSL query: the %s is defined by the metric of the query", key)
+		}
+	}
+	if query, ok := body["query"]; ok {
+		if _, ok := query.(map[string]interface{}); !ok {
+			return nil, errors.New("invalid raw DSL query: the query property should be a JSON object")
+		}
+	}
+	return body, nil
+}
+
+// addRawDSLQuery adds the query of a raw DSL search request body to the documents matching the time
+// range, and sets the other properties of the body, like timeout or track_total_hits, on the search request.
+// The properties set by the builder are rejected by parseRawDSLQuery.
+func addRawDSLQuery(b *es.SearchRequestBuilder, body map[string]interface{}) {
+	for key, value := range body {
+		if key != "query" {
+			b.CustomProp(key, value)
+		}
+	}
+
+	if query, ok := body["query"].(map[string]interface{}); ok {
+		b.Query().Bool().Must().AddRawQuery(query)
+	}
+}
@@ -3,18 +3,25 @@ package opensearch
 import (
 	"github.com/bitly/go-simplejson"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	es "github.com/grafana/opensearch-datasource/pkg/opensearch/client"
 )
 
 // Query represents the time series query model of the datasource
 type Query struct {
-	TimeField  string       `json:"timeField"`
-	RawQuery   string       `json:"query"`
-	QueryType  string       `json:"queryType"`
-	BucketAggs []*BucketAgg `json:"bucketAggs"`
-	Metrics    []*MetricAgg `json:"metrics"`
-	Alias      string       `json:"alias"`
-	Interval   string
-	RefID      string
+	TimeField       string           `json:"timeField"`
+	RawQuery        string           `json:"query"`
+	QueryType       string           `json:"queryType"`
+	BucketAggs      []*BucketAgg     `json:"bucketAggs"`
+	Metrics         []*MetricAgg     `json:"metrics"`
+	AdHocFilters    []es.AdHocFilter `json:"adhocFilters"`
+	Alias           string           `json:"alias"`
+	IsLogsQuery     bool             `json:"isLogsQuery"`
+	LuceneQueryType string           `json:"luceneQueryType"`
+	Format          string           `json:"format"`
+	MaxRows         int              `json:"maxRows"`
+	TimeZone        string           `json:"timeZone"`
+	Interval        string
+	RefID           string
 }
 
 // queryHandler is an interface for handling queries of the same type
@@ -44,20 +51,42 @@ type MetricAgg struct {
 }
 
 var metricAggType = map[string]string{
-	"count":          "Count",
-	"avg":            "Average",
-	"sum":            "Sum",
-	"max":            "Max",
-	"min":            "Min",
-	"extended_stats": "Extended Stats",
-	"percentiles":    "Percentiles",
-	"cardinality":    "Unique Count",
-	"moving_avg":     "Moving Average",
-	"moving_fn":      "Moving Function",
-	"cumulative_sum": "Cumulative Sum",
-	"derivative":     "Derivative",
-	"bucket_script":  "Bucket Script",
-	"raw_document":   "Raw Document",
+	"count":                     "Count",
+	"avg":                       "Average",
+	"sum":                       "Sum",
+	"max":                       "Max",
+	"min":                       "Min",
+	"extended_stats":            "Extended Stats",
+	"percentiles":               "Percentiles",
+	"cardinality":               "Unique Count",
+	"value_count":               "Value Count",
+	"stats":                     "Stats",
+	"percentile_ranks":          "Percentile Ranks",
+	"median_absolute_deviation": "Median Absolute Deviation",
+	"weighted_avg":              "Weighted Average",
+	"rate":                      "Rate",
+	"boxplot":                   "Boxplot",
+	"string_stats":              "String Stats",
+	"top_metrics":               "Top Metrics",
+	"geo_bounds":                "Geo Bounds",
+	"geo_centroid":              "Geo Centroid",
+	"moving_avg":                "Moving Average",
+	"moving_fn":                 "Moving Function",
+	"cumulative_sum":            "Cumulative Sum",
+	"derivative":                "Derivative",
+	"bucket_script":             "Bucket Script",
+	"serial_diff":               "Serial Difference",
+	"bucket_selector":           "Bucket Selector",
+	"bucket_sort":               "Bucket Sort",
+	"avg_bucket":                "Average Bucket",
+	"max_bucket":                "Max Bucket",
+	"min_bucket":                "Min Bucket",
+	"sum_bucket":                "Sum Bucket",
+	"percentiles_bucket":        "Percentiles Bucket",
+	"stats_bucket":              "Stats Bucket",
+	"raw_document":              "Raw Document",
+	"raw_data":                  "Raw Data",
+	"logs":                      "Logs",
 }
 
 var extendedStats = map[string]string{
@@ -71,16 +100,64 @@ var extendedStats = map[string]string{
 	"std_deviation_bounds_lower": "Std Dev Lower",
 }
 
+// metricValueNames names the values of the other metrics that return several values
+var metricValueNames = map[string]string{
+	"q1":         "Q1",
+	"q2":         "Median",
+	"q3":         "Q3",
+	"lower":      "Lower",
+	"upper":      "Upper",
+	"min_length": "Min Length",
+	"max_length": "Max Length",
+	"avg_length": "Avg Length",
+	"entropy":    "Entropy",
+	"lat":        "Latitude",
+	"lon":        "Longitude",
+
+	"top_left_lat":     "Top Left Latitude",
+	"top_left_lon":     "Top Left Longitude",
+	"bottom_right_lat": "Bottom Right Latitude",
+	"bottom_right_lon": "Bottom Right Longitude",
+}
+
+// the values of the metrics that return a fixed set of values, in the order of their series
+var (
+	statsValues       = []string{"count", "min", "max", "avg", "sum"}
+	boxplotValues     = []string{"min", "max", "q1", "q2", "q3", "lower", "upper"}
+	stringStatsValues = []string{"count", "min_length", "max_length", "avg_length", "entropy"}
+)
+
 var pipelineAggType = map[string]string{
-	"moving_avg":     "moving_avg",
-	"moving_fn":      "moving_fn",
-	"cumulative_sum": "cumulative_sum",
-	"derivative":     "derivative",
-	"bucket_script":  "bucket_script",
+	"moving_avg":         "moving_avg",
+	"moving_fn":          "moving_fn",
+	"cumulative_sum":     "cumulative_sum",
+	"derivative":         "derivative",
+	"bucket_script":      "bucket_script",
+	"serial_diff":        "serial_diff",
+	"bucket_selector":    "bucket_selector",
+	"bucket_sort":        "bucket_sort",
+	"avg_bucket":         "avg_bucket",
+	"max_bucket":         "max_bucket",
+	"min_bucket":         "min_bucket",
+	"sum_bucket":         "sum_bucket",
+	"percentiles_bucket": "percentiles_bucket",
+	"stats_bucket":       "stats_bucket",
 }
 
 var pipelineAggWithMultipleBucketPathsType = map[string]string{
-	"bucket_script": "bucket_script",
+	"bucket_script":   "bucket_script",
+	"bucket_selector": "bucket_selector",
+}
+
+// siblingPipelineAggType are the pipeline aggregations that aggregate all the buckets of the last
+// bucket aggregation into values next to it, instead of adding a value to each bucket
+var siblingPipelineAggType = map[string]string{
+	"avg_bucket":         "avg_bucket",
+	"max_bucket":         "max_bucket",
+	"min_bucket":         "min_bucket",
+	"sum_bucket":         "sum_bucket",
+	"percentiles_bucket": "percentiles_bucket",
+	"stats_bucket":       "stats_bucket",
 }
 
 func isPipelineAgg(metricType string) bool {
@@ -97,6 +174,42 @@ func isPipelineAggWithMultipleBucketPaths(metricType string) bool {
 	return false
 }
 
+func isRangeAgg(bucketAggType string) bool {
+	return bucketAggType == rangeType || bucketAggType == dateRangeType || bucketAggType == ipRangeType
+}
+
+// isGeoGridAgg returns true for the bucket aggregations whose buckets are the cells of a grid over a map
+func isGeoGridAgg(bucketAggType string) bool {
+	return bucketAggType == geohashGridType || bucketAggType == geotileGridType || bucketAggType == geohexGridType
+}
+
+// isCompoundKeyAgg returns true for the bucket aggregations whose buckets are keyed by several fields
+func isCompoundKeyAgg(bucketAggType string) bool {
+	return bucketAggType == compositeType || bucketAggType == multiTermsType
+}
+
+// getKeyFields returns the fields of the keys of a composite or multi_terms aggregation, which are the
+// field of the aggregation when no fields are set
+func getKeyFields(bucketAgg *BucketAgg) []string {
+	if fields := bucketAgg.Settings.Get("fields").MustStringArray(); len(fields) > 0 {
+		return fields
+	}
+	return []string{bucketAgg.Field}
+}
+
+func isSiblingPipelineAgg(metricType string) bool {
+	if _, ok := siblingPipelineAggType[metricType]; ok {
+		return true
+	}
+	return false
+}
+
+// addsBucketValues returns false for the pipeline aggregations that filter, sort or aggregate the
+// buckets of their parent instead of adding a value to each bucket
+func addsBucketValues(metricType string) bool {
+	return metricType != bucketSelectorType && metricType != bucketSortType && !isSiblingPipelineAgg(metricType)
+}
+
 func describeMetric(metricType, field string) string {
 	text := metricAggType[metricType]
 	if metricType == countType {
@@ -105,10 +218,53 @@ func describeMetric(metricType, field string) string {
 	return text + " " + field
 }
 
+// ConfiguredFields contains the datasource settings used to shape document based responses
+type ConfiguredFields struct {
+	TimeField       string
+	LogMessageField string
+	LogLevelField   string
+}
+
+func newConfiguredFields(settings *backend.DataSourceInstanceSettings, timeField string) ConfiguredFields {
+	configuredFields := ConfiguredFields{
+		TimeField: timeField,
+	}
+
+	if settings != nil {
+		if jsonData, err := simplejson.NewJson(settings.JSONData); err == nil {
+			configuredFields.LogMessageField = jsonData.Get("logMessageField").MustString()
+			configuredFields.LogLevelField = jsonData.Get("logLevelField").MustString()
+		}
+	}
+
+	return configuredFields
+}
+
 // Query Types
 const (
 	Lucene = "lucene"
 	PPL    = "PPL"
+	SQL    = "SQL"
+)
+
+// Lucene query types
+const (
+	luceneQueryTypeTraces = "Traces"
+	luceneQueryTypeRawDSL = "RawDSL"
+)
+
+// PPL output formats
+const (
+	pplFormatTable      = "table"
+	pplFormatLogs       = "logs"
+	pplFormatTimeSeries = "time_series"
+)
+
+const (
+	// defaultMaxRows is the number of rows a PPL or SQL query returns at most when the query has no row limit
+	defaultMaxRows = 10000
+	// pplPageSize is the number of rows of each page of a PPL or SQL response
+	pplPageSize = 1000
 )
 
 // PPL date time type formats
@@ -3,10 +3,12 @@ package opensearch
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
 	es "github.com/grafana/opensearch-datasource/pkg/opensearch/client"
 	"github.com/grafana/opensearch-datasource/pkg/tsdb"
 )
@@ -22,16 +24,37 @@ type TsdbQueryEndpoint interface {
 	Query(ctx context.Context, ds *backend.DataSourceInstanceSettings, query *tsdb.TsdbQuery) (*tsdb.Response, error)
 }
 
+var _ instancemgmt.InstanceDisposer = (*OpenSearchDatasource)(nil)
+
 type OpenSearchDatasource struct {
-	dsInfo *backend.DataSourceInstanceSettings
+	dsInfo          *es.DatasourceInfo
+	resourceHandler backend.CallResourceHandler
 }
 
 func NewOpenSearchDatasource(settings backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
 	log.DefaultLogger.Debug("Initializing new data source instance")
 
-	return &OpenSearchDatasource{
-		dsInfo: &settings,
-	}, nil
+	dsInfo, err := es.NewDatasourceInfo(&settings)
+	if err != nil {
+		return nil, fmt.Errorf("invalid data source settings: %w", err)
+	}
+
+	ds := &OpenSearchDatasource{
+		dsInfo: dsInfo,
+	}
+	ds.resourceHandler = httpadapter.New(ds.newResourceMux())
+	return ds, nil
+}
+
+// Dispose closes the idle connections of the HTTP client when the instance is replaced after a settings change
+func (ds *OpenSearchDatasource) Dispose() {
+	log.DefaultLogger.Debug("Disposing data source instance")
+	ds.dsInfo.HTTPClient.CloseIdleConnections()
+}
+
+// CallResource handles the resource requests of the query editor, like the fields and terms of the indices
+func (ds *OpenSearchDatasource) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	return ds.resourceHandler.CallResource(ctx, req, sender)
 }
 
 // CheckHealth handles health checks sent from Grafana to the plugin.
@@ -39,11 +62,36 @@ func NewOpenSearchDatasource(settings backend.DataSourceInstanceSettings) (insta
 // datasource configuration page which allows users to verify that
 // a datasource is working as expected.
 func (ds *OpenSearchDatasource) CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
-	res := &backend.CheckHealthResult{}
+	now := time.Now()
+	timeRange := backend.TimeRange{From: now.Add(-healthCheckTimeRange), To: now}
+	client, err := es.NewClient(ctx, ds.dsInfo, &timeRange)
+	if err != nil {
+		return healthCheckError("invalid data source settings: %s", err), nil
+	}
+
+	jsonData := ds.dsInfo.JSONData
+
+	// serverless collections don't expose the root endpoint
+	if !jsonData.Get("serverless").MustBool(false) {
+		if err := checkVersion(client); err != nil {
+			return healthCheckError("%s", err), nil
+		}
+	}
 
-	res.Status = backend.HealthStatusOk
-	res.Message = "plugin is running"
-	return res, nil
+	if err := checkTimeField(client, ds.dsInfo.Database); err != nil {
+		return healthCheckError("%s", err), nil
+	}
+
+	if jsonData.Get("pplEnabled").MustBool(true) {
+		if err := checkPPL(client); err != nil {
+			return healthCheckError("%s", err), nil
+		}
+	}
+
+	return &backend.CheckHealthResult{
+		Status:  backend.HealthStatusOk,
+		Message: "Index OK. Time field name OK.",
+	}, nil
 }
 
 // QueryData handles multiple queries and returns multiple responses.
@@ -56,7 +104,7 @@ func (ds *OpenSearchDatasource) QueryData(ctx context.Context, req *backend.Quer
 	}
 
 	timeRange := req.Queries[0].TimeRange
-	client, err := es.NewClient(ctx, req.PluginContext.DataSourceInstanceSettings, &timeRange)
+	client, err := es.NewClient(ctx, ds.dsInfo, &timeRange)
 	if err != nil {
 		return nil, err
 	}
@@ -1,7 +1,11 @@
 package opensearch
 
 import (
+	"fmt"
+
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	es "github.com/grafana/opensearch-datasource/pkg/opensearch/client"
 )
 
@@ -9,6 +13,7 @@ type pplHandler struct {
 	client   es.Client
 	req      *backend.QueryDataRequest
 	builders map[string]*es.PPLRequestBuilder
+	queries  map[string]*Query
 }
 
 var newPPLHandler = func(client es.Client, req *backend.QueryDataRequest) *pplHandler {
@@ -16,6 +21,7 @@ var newPPLHandler = func(client es.Client, req *backend.QueryDataRequest) *pplHa
 		client:   client,
 		req:      req,
 		builders: make(map[string]*es.PPLRequestBuilder),
+		queries:  make(map[string]*Query),
 	}
 }
 
@@ -25,28 +31,107 @@ func (h *pplHandler) processQuery(q *Query) error {
 
 	builder := h.client.PPL()
 	builder.AddPPLQueryString(h.client.GetTimeField(), to, from, q.RawQuery)
+	builder.AddAdHocFilters(q.AdHocFilters)
+	builder.FetchSize(pageSize(q.MaxRows))
 	h.builders[q.RefID] = builder
+	h.queries[q.RefID] = q
 	return nil
 }
 
 func (h *pplHandler) executeQueries() (*backend.QueryDataResponse, error) {
-	result := backend.NewQueryDataResponse()
+	return executeConcurrently(h.queries, func(q *Query) backend.DataResponse {
+		return h.executeQuery(q, h.builders[q.RefID])
+	}), nil
+}
 
-	for refID, builder := range h.builders {
-		req, err := builder.Build()
+// executeQuery sends the PPL request of a query, a failure only sets the error of the query's response
+func (h *pplHandler) executeQuery(q *Query, builder *es.PPLRequestBuilder) backend.DataResponse {
+	req, err := builder.Build()
+	if err != nil {
+		return queryErrorResponse(err)
+	}
+	res, err := h.client.ExecutePPLQuery(req)
+	if err != nil {
+		return downstreamErrorResponse(err)
+	}
+	truncated, err := fetchPages(h.client, es.LanguagePPL, res, q.MaxRows)
+	if err != nil {
+		return downstreamErrorResponse(err)
+	}
+
+	rp := newPPLResponseParser(res)
+	var queryRes *backend.DataResponse
+	switch {
+	case q.IsLogsQuery || q.Format == pplFormatLogs:
+		queryRes, err = rp.parseLogs(newConfiguredFields(h.req.PluginContext.DataSourceInstanceSettings, h.client.GetTimeField()))
+	case q.Format == pplFormatTable:
+		queryRes, err = rp.parseTable()
+	default:
+		queryRes, err = rp.parseTimeSeries()
+	}
+	if err != nil {
+		// the response doesn't have the shape the format of the query requires
+		return queryErrorResponse(err)
+	}
+	if truncated {
+		addTruncationNotice(queryRes, len(res.Datarows), res.Total)
+	}
+	return *queryRes
+}
+
+// pageSize returns the number of rows of the pages of a query, the pages are smaller when the query
+// returns fewer rows
+func pageSize(maxRows int) int {
+	if maxRows < pplPageSize {
+		return maxRows
+	}
+	return pplPageSize
+}
+
+// fetchPages adds the rows of the next pages to the first page of a PPL or SQL response, until the
+// response has no cursor or has maxRows rows. It returns whether the response is missing rows. The
+// cursor of the remaining pages is closed.
+func fetchPages(client es.Client, language es.QueryLanguage, res *es.PPLResponse, maxRows int) (bool, error) {
+	for res.Cursor != "" && len(res.Datarows) < maxRows {
+		page, err := client.FetchNextPage(language, res.Cursor)
 		if err != nil {
-			return nil, err
+			return false, err
 		}
-		res, err := h.client.ExecutePPLQuery(req)
-		if err != nil {
-			return nil, err
+		if page.Error != nil {
+			return false, fmt.Errorf("failed to fetch the next page of the response: %v", page.Error["reason"])
 		}
-		rp := newPPLResponseParser(res)
-		queryRes, err := rp.parseTimeSeries()
-		if err != nil {
-			return nil, err
+		res.Datarows = append(res.Datarows, page.Datarows...)
+		res.Cursor = page.Cursor
+	}
+
+	truncated := res.Cursor != "" || len(res.Datarows) > maxRows || res.Total > len(res.Datarows)
+	if len(res.Datarows) > maxRows {
+		res.Datarows = res.Datarows[:maxRows]
+	}
+	if res.Cursor != "" {
+		if err := client.CloseCursor(language, res.Cursor); err != nil {
+			// the cluster releases the cursor when it expires
+			log.DefaultLogger.Warn("Failed to close the cursor", "error", err)
+		}
+		res.Cursor = ""
+	}
+	return truncated, nil
+}
+
+// addTruncationNotice warns that the frames of a response don't have all the rows of the query
+func addTruncationNotice(res *backend.DataResponse, rows, total int) {
+	text := fmt.Sprintf("Showing the first %d rows, increase the row limit of the query to show more", rows)
+	if total > rows {
+		text = fmt.Sprintf("Showing the first %d rows of %d, increase the row limit of the query to show more", rows, total)
+	}
+
+	for _, frame := range res.Frames {
+		if frame.Meta == nil {
+			frame.Meta = &data.FrameMeta{}
 		}
-		result.Responses[refID] = *queryRes
+		frame.Meta.Notices = append(frame.Meta.Notices, data.Notice{
+			Severity: data.NoticeSeverityWarning,
+			Text:     text,
+		})
 	}
-	return result, nil
 }
@@ -1,8 +1,10 @@
 package opensearch
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	simplejson "github.com/bitly/go-simplejson"
@@ -25,28 +27,15 @@ var newPPLResponseParser = func(response *es.PPLResponse) *pplResponseParser {
 
 // Stores meta info on response object
 type responseMeta struct {
-	valueIndex      int
+	valueIndexes    []int
+	labelIndexes    []int
 	timeFieldIndex  int
 	timeFieldFormat string
 }
 
 func (rp *pplResponseParser) parseTimeSeries() (*backend.DataResponse, error) {
-	var debugInfo *simplejson.Json
-	if rp.Response.DebugInfo != nil {
-		debugInfo = utils.NewJsonFromAny(rp.Response.DebugInfo)
-	}
-
 	if rp.Response.Error != nil {
-		return &backend.DataResponse{
-			Error: getErrorFromPPLResponse(rp.Response),
-			Frames: []*data.Frame{
-				{
-					Meta: &data.FrameMeta{
-						Custom: debugInfo,
-					},
-				},
-			},
-		}, nil
+		return rp.getErrorResponse(), nil
 	}
 
 	queryRes := &backend.DataResponse{
@@ -58,43 +47,196 @@ func (rp *pplResponseParser) parseTimeSeries() (*backend.DataResponse, error) {
 		return nil, err
 	}
 
-	newFrame := data.NewFrame(rp.getSeriesName(t.valueIndex),
-		data.NewFieldFromFieldType(data.FieldTypeNullableTime, len(rp.Response.Datarows)),
-		data.NewFieldFromFieldType(data.FieldTypeNullableFloat64, len(rp.Response.Datarows)),
-	)
-
+	// one series per value field and combination of label values, in the order they are found
+	series := make(map[string]*data.Frame)
 	for i, datarow := range rp.Response.Datarows {
-		err := rp.addDatarow(newFrame, i, datarow, t)
+		// the time, value and label fields are read by their position in the schema
+		if len(datarow) < len(rp.Response.Schema) {
+			return nil, fmt.Errorf("datarow %d has %d values but the schema has %d fields", i, len(datarow), len(rp.Response.Schema))
+		}
+		timestamp, err := rp.parseTimestamp(datarow[t.timeFieldIndex], t.timeFieldFormat)
 		if err != nil {
 			return nil, err
 		}
-	}
+		labels := rp.getLabels(datarow, t)
 
-	queryRes.Frames = append(queryRes.Frames, newFrame)
+		for _, valueIndex := range t.valueIndexes {
+			value, err := rp.parseValue(datarow[valueIndex])
+			if err != nil {
+				return nil, err
+			}
+
+			key := strconv.Itoa(valueIndex) + labels.String()
+			frame, ok := series[key]
+			if !ok {
+				frame = data.NewFrame(rp.getSeriesName(valueIndex),
+					data.NewFieldFromFieldType(data.FieldTypeNullableTime, 0),
+					data.NewFieldFromFieldType(data.FieldTypeNullableFloat64, 0),
+				)
+				frame.Fields[1].Labels = labels
+				series[key] = frame
+				queryRes.Frames = append(queryRes.Frames, frame)
+			}
+
+			var v *float64
+			if value.Valid {
+				v = &value.Float64
+			}
+			frame.AppendRow(utils.NullFloatToNullableTime(timestamp), v)
+		}
+	}
 
 	return queryRes, nil
 }
 
-func (rp *pplResponseParser) addDatarow(frame *data.Frame, i int, datarow es.Datarow, t responseMeta) error {
-	value, err := rp.parseValue(datarow[t.valueIndex])
-	if err != nil {
-		return err
+// parseTable returns the PPL result set as a single frame with one typed field per schema field
+func (rp *pplResponseParser) parseTable() (*backend.DataResponse, error) {
+	if rp.Response.Error != nil {
+		return rp.getErrorResponse(), nil
 	}
-	timestamp, err := rp.parseTimestamp(datarow[t.timeFieldIndex], t.timeFieldFormat)
-	if err != nil {
-		return err
+
+	fields := make([]*data.Field, len(rp.Response.Schema))
+	for i, fieldSchema := range rp.Response.Schema {
+		fields[i] = newPPLField(fieldSchema, rp.Response.Datarows, i)
 	}
-	
-	frame.Set(0, i, utils.NullFloatToNullableTime(timestamp))
-	if value.Valid {
-		frame.Set(1, i, &value.Float64)
-	} else {
-		frame.Set(1, i, nil)
+
+	return &backend.DataResponse{
+		Frames: data.Frames{data.NewFrame("", fields...)},
+	}, nil
+}
+
+// parseLogs returns the PPL result set as log lines. Struct fields are flattened into a field per
+// nested property, the same way as the documents of Lucene logs queries.
+func (rp *pplResponseParser) parseLogs(configuredFields ConfiguredFields) (*backend.DataResponse, error) {
+	if rp.Response.Error != nil {
+		return rp.getErrorResponse(), nil
+	}
+
+	docs := make([]map[string]interface{}, len(rp.Response.Datarows))
+	propNames := make(map[string]bool)
+	for i, datarow := range rp.Response.Datarows {
+		doc := make(map[string]interface{})
+		for j, fieldSchema := range rp.Response.Schema {
+			if j < len(datarow) {
+				doc[fieldSchema.Name] = datarow[j]
+			}
+		}
+		docs[i] = utils.FlattenNestedObject(doc)
+		for k := range docs[i] {
+			propNames[k] = true
+		}
+	}
+
+	frame := data.NewFrame("", createDocumentFields(docs, sortedKeys(propNames), configuredFields, true)...)
+	frame.Meta = &data.FrameMeta{
+		PreferredVisualization: data.VisTypeLogs,
+	}
+
+	return &backend.DataResponse{
+		Frames: data.Frames{frame},
+	}, nil
+}
+
+func (rp *pplResponseParser) getErrorResponse() *backend.DataResponse {
+	var debugInfo *simplejson.Json
+	if rp.Response.DebugInfo != nil {
+		debugInfo = utils.NewJsonFromAny(rp.Response.DebugInfo)
+	}
+
+	return &backend.DataResponse{
+		Error:  getErrorFromPPLResponse(rp.Response),
+		Status: responseErrorStatus(rp.Response.Status),
+		Frames: []*data.Frame{
+			{
+				Meta: &data.FrameMeta{
+					Custom: debugInfo,
+				},
+			},
+		},
 	}
-	return nil
+}
+
+// newPPLField creates the field of a PPL schema field, with a type based on the PPL data type
+// https://opensearch.org/docs/latest/search-plugins/sql/datatypes/
+func newPPLField(fieldSchema es.FieldSchema, datarows []es.Datarow, index int) *data.Field {
+	fieldType := getPPLFieldType(fieldSchema.Type)
+	field := data.NewFieldFromFieldType(fieldType, len(datarows))
+	field.Name = fieldSchema.Name
+
+	for i, datarow := range datarows {
+		if index >= len(datarow) || datarow[index] == nil {
+			continue
+		}
+		value := datarow[index]
+
+		switch fieldType {
+		case data.FieldTypeNullableInt64:
+			if number, ok := value.(float64); ok {
+				v := int64(number)
+				field.Set(i, &v)
+			}
+		case data.FieldTypeNullableFloat64:
+			if number, ok := value.(float64); ok {
+				field.Set(i, &number)
+			}
+		case data.FieldTypeNullableBool:
+			if b, ok := value.(bool); ok {
+				field.Set(i, &b)
+			}
+		case data.FieldTypeNullableTime:
+			field.Set(i, parseTimeValue(value))
+		case data.FieldTypeNullableJSON:
+			raw := json.RawMessage(utils.NewRawJsonFromAny(value))
+			field.Set(i, &raw)
+		default:
+			field.Set(i, toNullableString(value))
+		}
+	}
+
+	return field
+}
+
+func getPPLFieldType(pplType string) data.FieldType {
+	switch pplType {
+	case "byte", "short", "integer", "long":
+		return data.FieldTypeNullableInt64
+	case "float", "double":
+		return data.FieldTypeNullableFloat64
+	case "boolean":
+		return data.FieldTypeNullableBool
+	case "timestamp", "datetime", "date":
+		return data.FieldTypeNullableTime
+	case "struct", "array":
+		return data.FieldTypeNullableJSON
+	default:
+		return data.FieldTypeNullableString
+	}
+}
+
+// getLabels returns the values of the label fields of a datarow, or nil when there are no label fields. The
+// datarow should have a value for each field of the schema.
+func (rp *pplResponseParser) getLabels(datarow es.Datarow, t responseMeta) data.Labels {
+	if len(t.labelIndexes) == 0 {
+		return nil
+	}
+
+	labels := data.Labels{}
+	for _, labelIndex := range t.labelIndexes {
+		value := ""
+		if datarow[labelIndex] != nil {
+			if v := toNullableString(datarow[labelIndex]); v != nil {
+				value = *v
+			}
+		}
+		labels[rp.Response.Schema[labelIndex].Name] = value
+	}
+	return labels
 }
 
 func (rp *pplResponseParser) parseValue(value interface{}) (null.Float, error) {
+	if value == nil {
+		return null.FloatFromPtr(nil), nil
+	}
 	number, ok := value.(float64)
 	if !ok {
 		return null.FloatFromPtr(nil), errors.New("found non-numerical value in value field")
@@ -119,28 +261,40 @@ func (rp *pplResponseParser) getSeriesName(valueIndex int) string {
 	return schema[valueIndex].Name
 }
 
+// getResponseMeta finds the time field of a time series response. The first time field is used as the
+// time of the series, number fields are used as values and all other fields are used as labels.
 func getResponseMeta(schema []es.FieldSchema) (responseMeta, error) {
-	if len(schema) != 2 {
-		return responseMeta{}, fmt.Errorf("response should have 2 fields but found %v", len(schema))
+	if len(schema) < 2 {
+		return responseMeta{}, fmt.Errorf("response should have at least 2 fields but found %v", len(schema))
 	}
-	var timeIndex int
-	var format string
-	found := false
+	t := responseMeta{timeFieldIndex: -1}
 	for i, field := range schema {
-		if field.Type == "timestamp" || field.Type == "datetime" || field.Type == "date" {
-			timeIndex = i
-			found = true
+		switch {
+		case t.timeFieldIndex == -1 && (field.Type == "timestamp" || field.Type == "datetime" || field.Type == "date"):
+			t.timeFieldIndex = i
 			if field.Type == "date" {
-				format = pplDateFormat
+				t.timeFieldFormat = pplDateFormat
 			} else {
-				format = pplTSFormat
+				t.timeFieldFormat = pplTSFormat
 			}
+		case isPPLNumberType(field.Type):
+			t.valueIndexes = append(t.valueIndexes, i)
+		default:
+			t.labelIndexes = append(t.labelIndexes, i)
 		}
 	}
-	if !found {
+	if t.timeFieldIndex == -1 {
 		return responseMeta{}, errors.New("a valid time field type was not found in response")
 	}
-	return responseMeta{valueIndex: 1 - timeIndex, timeFieldIndex: timeIndex, timeFieldFormat: format}, nil
+	if len(t.valueIndexes) == 0 {
+		return responseMeta{}, errors.New("a valid value field type was not found in response")
+	}
+	return t, nil
+}
+
+func isPPLNumberType(pplType string) bool {
+	fieldType := getPPLFieldType(pplType)
+	return fieldType == data.FieldTypeNullableInt64 || fieldType == data.FieldTypeNullableFloat64
 }
 
 func getErrorFromPPLResponse(response *es.PPLResponse) error {
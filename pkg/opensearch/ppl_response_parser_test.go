@@ -112,6 +112,81 @@ func TestPPLResponseParser(t *testing.T) {
 			So(frame.Name, ShouldEqual, "valueField")
 		})
 
+		Convey("Multiple value fields", func() {
+			targets := map[string]string{
+				"A": `{
+							"timeField": "@timestamp"
+						}`,
+			}
+			response := `{
+						"schema": [
+							{ "name": "testMetric", "type": "integer" },
+							{ "name": "extraMetric", "type": "double" },
+							{ "name": "timeName", "type": "timestamp" }
+						],
+						"datarows": [
+							[20, 2.5, "%s"],
+							[30, null, "%s"]
+						],
+						"total": 2,
+						"size": 2
+					}`
+			response = fmt.Sprintf(response, formatUnixMs(100, pplTSFormat), formatUnixMs(200, pplTSFormat))
+			rp, err := newPPLResponseParserForTest(targets, response)
+			So(err, ShouldBeNil)
+			queryRes, err := rp.parseTimeSeries()
+			So(err, ShouldBeNil)
+			So(queryRes.Frames, ShouldHaveLength, 2)
+			So(queryRes.Frames[0].Name, ShouldEqual, "testMetric")
+			So(queryRes.Frames[0].Rows(), ShouldEqual, 2)
+			So(floatAt(queryRes.Frames[0], 1, 1), ShouldEqual, 30)
+			So(queryRes.Frames[1].Name, ShouldEqual, "extraMetric")
+			So(floatAt(queryRes.Frames[1], 1, 0), ShouldEqual, 2.5)
+			So(queryRes.Frames[1].Fields[1].At(1), ShouldBeNil)
+		})
+
+		Convey("Group by fields as labels", func() {
+			targets := map[string]string{
+				"A": `{
+							"timeField": "@timestamp"
+						}`,
+			}
+			response := `{
+						"schema": [
+							{ "name": "avg(bytes)", "type": "double" },
+							{ "name": "span(@timestamp,1m)", "type": "timestamp" },
+							{ "name": "host", "type": "string" }
+						],
+						"datarows": [
+							[10, "%[1]s", "server-1"],
+							[20, "%[1]s", "server-2"],
+							[30, "%[2]s", "server-1"]
+						],
+						"total": 3,
+						"size": 3
+					}`
+			response = fmt.Sprintf(response, formatUnixMs(100, pplTSFormat), formatUnixMs(200, pplTSFormat))
+			rp, err := newPPLResponseParserForTest(targets, response)
+			So(err, ShouldBeNil)
+			queryRes, err := rp.parseTimeSeries()
+			So(err, ShouldBeNil)
+			So(queryRes.Frames, ShouldHaveLength, 2)
+
+			serverOne := queryRes.Frames[0]
+			So(serverOne.Name, ShouldEqual, "avg(bytes)")
+			So(serverOne.Fields[1].Labels, ShouldResemble, data.Labels{"host": "server-1"})
+			So(serverOne.Rows(), ShouldEqual, 2)
+			So(floatAt(serverOne, 0, 0), ShouldEqual, 100)
+			So(floatAt(serverOne, 1, 0), ShouldEqual, 10)
+			So(floatAt(serverOne, 0, 1), ShouldEqual, 200)
+			So(floatAt(serverOne, 1, 1), ShouldEqual, 30)
+
+			serverTwo := queryRes.Frames[1]
+			So(serverTwo.Fields[1].Labels, ShouldResemble, data.Labels{"host": "server-2"})
+			So(serverTwo.Rows(), ShouldEqual, 1)
+			So(floatAt(serverTwo, 1, 0), ShouldEqual, 20)
+		})
+
 		Convey("Different date formats", func() {
 			targets := map[string]string{
 				"A": `{
@@ -177,31 +252,6 @@ func TestPPLResponseParser(t *testing.T) {
 		})
 
 		Convey("Handle invalid schema for time series", func() {
-			Convey("More than two fields", func() {
-				targets := map[string]string{
-					"A": `{
-								"timeField": "@timestamp"
-							}`,
-				}
-				response := `{
-						"schema": [
-							{ "name": "testMetric", "type": "integer" },
-							{ "name": "extraMetric", "type": "integer" },
-							{ "name": "timeName", "type": "timestamp" }
-						],
-						"datarows": [
-							[20, 20, "%s"]
-						],
-						"total": 1,
-						"size": 1
-					}`
-				response = fmt.Sprintf(response, formatUnixMs(100, pplTSFormat))
-				rp, err := newPPLResponseParserForTest(targets, response)
-				So(err, ShouldBeNil)
-				_, err = rp.parseTimeSeries()
-				So(err, ShouldNotBeNil)
-			})
-
 			Convey("Less than two fields", func() {
 				targets := map[string]string{
 					"A": `{
@@ -318,6 +368,119 @@ func TestPPLResponseParser(t *testing.T) {
 				_, err = rp.parseTimeSeries()
 				So(err, ShouldNotBeNil)
 			})
+
+			Convey("Datarow shorter than the schema", func() {
+				targets := map[string]string{
+					"A": `{
+								"timeField": "@timestamp"
+							}`,
+				}
+				response := `{
+							"schema": [
+								{ "name": "avg(bytes)", "type": "double" },
+								{ "name": "span(@timestamp,1m)", "type": "timestamp" },
+								{ "name": "host", "type": "string" }
+							],
+							"datarows": [
+								[10, "%s"]
+							],
+							"total": 1,
+							"size": 1
+						}`
+				response = fmt.Sprintf(response, formatUnixMs(100, pplTSFormat))
+				rp, err := newPPLResponseParserForTest(targets, response)
+				So(err, ShouldBeNil)
+				_, err = rp.parseTimeSeries()
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldEqual, "datarow 0 has 2 values but the schema has 3 fields")
+			})
+		})
+
+		Convey("Table format", func() {
+			targets := map[string]string{
+				"A": `{
+					"timeField": "@timestamp",
+					"format": "table"
+				}`,
+			}
+			response := `{
+				"schema": [
+					{ "name": "host", "type": "string" },
+					{ "name": "count()", "type": "integer" },
+					{ "name": "avg(bytes)", "type": "double" },
+					{ "name": "success", "type": "boolean" },
+					{ "name": "timestamp", "type": "timestamp" },
+					{ "name": "geo", "type": "struct" }
+				],
+				"datarows": [
+					["server-1", 10, 2.5, true, "%s", { "lat": 1.5 }],
+					["server-2", 15, null, false, "%s", null]
+				],
+				"total": 2,
+				"size": 2
+			}`
+			response = fmt.Sprintf(response, formatUnixMs(100, pplTSFormat), formatUnixMs(200, pplTSFormat))
+			rp, err := newPPLResponseParserForTest(targets, response)
+			So(err, ShouldBeNil)
+			queryRes, err := rp.parseTable()
+			So(err, ShouldBeNil)
+			So(queryRes.Frames, ShouldHaveLength, 1)
+			frame := queryRes.Frames[0]
+			So(frame.Rows(), ShouldEqual, 2)
+			So(frame.Fields, ShouldHaveLength, 6)
+
+			So(frame.Fields[0].Name, ShouldEqual, "host")
+			So(frame.Fields[0].Type(), ShouldEqual, data.FieldTypeNullableString)
+			So(*frame.Fields[0].At(1).(*string), ShouldEqual, "server-2")
+			So(frame.Fields[1].Type(), ShouldEqual, data.FieldTypeNullableInt64)
+			So(*frame.Fields[1].At(0).(*int64), ShouldEqual, 10)
+			So(frame.Fields[2].Type(), ShouldEqual, data.FieldTypeNullableFloat64)
+			So(*frame.Fields[2].At(0).(*float64), ShouldEqual, 2.5)
+			So(frame.Fields[2].At(1), ShouldBeNil)
+			So(frame.Fields[3].Type(), ShouldEqual, data.FieldTypeNullableBool)
+			So(*frame.Fields[3].At(1).(*bool), ShouldBeFalse)
+			So(frame.Fields[4].Type(), ShouldEqual, data.FieldTypeNullableTime)
+			So(frame.Fields[4].At(1).(*time.Time).UnixMilli(), ShouldEqual, 200)
+			So(frame.Fields[5].Type(), ShouldEqual, data.FieldTypeNullableJSON)
+			So(string(*frame.Fields[5].At(0).(*json.RawMessage)), ShouldEqual, `{"lat":1.5}`)
+		})
+
+		Convey("Logs format", func() {
+			targets := map[string]string{
+				"A": `{
+					"timeField": "@timestamp",
+					"format": "logs"
+				}`,
+			}
+			response := `{
+				"schema": [
+					{ "name": "@timestamp", "type": "timestamp" },
+					{ "name": "message", "type": "string" },
+					{ "name": "fields", "type": "struct" }
+				],
+				"datarows": [
+					["%s", "first line", { "lvl": "info" }],
+					["%s", "second line", { "lvl": "error" }]
+				],
+				"total": 2,
+				"size": 2
+			}`
+			response = fmt.Sprintf(response, formatUnixMs(100, pplTSFormat), formatUnixMs(200, pplTSFormat))
+			rp, err := newPPLResponseParserForTest(targets, response)
+			So(err, ShouldBeNil)
+			queryRes, err := rp.parseLogs(ConfiguredFields{TimeField: "@timestamp", LogMessageField: "message", LogLevelField: "fields.lvl"})
+			So(err, ShouldBeNil)
+			So(queryRes.Frames, ShouldHaveLength, 1)
+			frame := queryRes.Frames[0]
+			So(string(frame.Meta.PreferredVisualization), ShouldEqual, data.VisTypeLogs)
+			So(frame.Rows(), ShouldEqual, 2)
+			So(frame.Fields[0].Name, ShouldEqual, "@timestamp")
+			So(frame.Fields[0].At(0).(*time.Time).UnixMilli(), ShouldEqual, 100)
+			So(frame.Fields[1].Name, ShouldEqual, "message")
+			So(*frame.Fields[1].At(1).(*string), ShouldEqual, "second line")
+			So(frame.Fields[2].Name, ShouldEqual, "level")
+			So(*frame.Fields[2].At(1).(*string), ShouldEqual, "error")
+			So(frame.Fields[3].Name, ShouldEqual, "fields.lvl")
 		})
 
 		Convey("Parses error response", func() {
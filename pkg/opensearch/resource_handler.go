@@ -0,0 +1,310 @@
+package opensearch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	es "github.com/grafana/opensearch-datasource/pkg/opensearch/client"
+	"github.com/grafana/opensearch-datasource/pkg/tsdb"
+	"github.com/grafana/opensearch-datasource/pkg/utils"
+)
+
+const (
+	// defaultResourceTimeRange is used to resolve the indices when a resource request has no time range
+	defaultResourceTimeRange = time.Hour
+	defaultTermsSize         = 500
+)
+
+// metaFields are the mapping fields that are never returned as document fields
+var metaFields = map[string]bool{
+	"_index":        true,
+	"_type":         true,
+	"_id":           true,
+	"_source":       true,
+	"_size":         true,
+	"_field_names":  true,
+	"_ignored":      true,
+	"_routing":      true,
+	"_meta":         true,
+	"_data_stream":  true,
+	"_doc_count":    true,
+	"_tier":         true,
+	"_seq_no":       true,
+	"_primary_term": true,
+}
+
+// fieldTypeGroups maps the mapping types to the type groups used by the query editor
+var fieldTypeGroups = map[string]string{
+	"float":        "number",
+	"double":       "number",
+	"integer":      "number",
+	"long":         "number",
+	"scaled_float": "number",
+	"date":         "date",
+	"date_nanos":   "date",
+	"string":       "string",
+	"text":         "string",
+	"nested":       "nested",
+}
+
+type fieldResource struct {
+	Text string `json:"text"`
+	Type string `json:"type"`
+}
+
+type termResource struct {
+	Text  string      `json:"text"`
+	Value interface{} `json:"value"`
+}
+
+type versionResource struct {
+	Flavor  es.Flavor `json:"flavor"`
+	Version string    `json:"version"`
+}
+
+func (ds *OpenSearchDatasource) newResourceMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/fields", ds.handleFields)
+	mux.HandleFunc("/terms", ds.handleTerms)
+	mux.HandleFunc("/indices", ds.handleIndices)
+	mux.HandleFunc("/version", ds.handleVersion)
+	return mux
+}
+
+// handleFields returns the fields of the indices, optionally filtered by `type` which is either a
+// mapping type or one of the type groups of fieldTypeGroups
+func (ds *OpenSearchDatasource) handleFields(rw http.ResponseWriter, req *http.Request) {
+	client, err := ds.newResourceClient(req)
+	if err != nil {
+		writeResourceError(rw, http.StatusBadRequest, err)
+		return
+	}
+
+	mapping, err := client.GetMapping()
+	if err != nil {
+		writeResourceError(rw, http.StatusInternalServerError, err)
+		return
+	}
+
+	fieldType := req.URL.Query().Get("type")
+	fields := make(map[string]string)
+	for _, indexMapping := range mapping {
+		collectMappingFields(getIndexProperties(indexMapping), "", fields)
+	}
+
+	result := make([]fieldResource, 0, len(fields))
+	for name, t := range fields {
+		if fieldType == "" || fieldType == t || fieldType == fieldTypeGroups[t] {
+			result = append(result, fieldResource{Text: name, Type: t})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Text < result[j].Text
+	})
+
+	writeResourceResponse(rw, result)
+}
+
+// handleTerms returns the values of `field` in the documents matching `query`, ordered by key or doc_count
+func (ds *OpenSearchDatasource) handleTerms(rw http.ResponseWriter, req *http.Request) {
+	params := req.URL.Query()
+	field := params.Get("field")
+	if field == "" {
+		writeResourceError(rw, http.StatusBadRequest, errors.New("field is required"))
+		return
+	}
+
+	size := defaultTermsSize
+	if s := params.Get("size"); s != "" {
+		var err error
+		if size, err = strconv.Atoi(s); err != nil || size <= 0 {
+			writeResourceError(rw, http.StatusBadRequest, fmt.Errorf("invalid size %q", s))
+			return
+		}
+	}
+
+	orderBy := params.Get("orderBy")
+	if orderBy == "" {
+		orderBy = "key"
+	}
+	order := params.Get("order")
+	if order == "" {
+		order = "asc"
+		if orderBy == "doc_count" {
+			order = "desc"
+		}
+	}
+	if order != "asc" && order != "desc" {
+		writeResourceError(rw, http.StatusBadRequest, fmt.Errorf("invalid sort order %q", order))
+		return
+	}
+
+	client, err := ds.newResourceClient(req)
+	if err != nil {
+		writeResourceError(rw, http.StatusBadRequest, err)
+		return
+	}
+
+	timeRange := getResourceTimeRange(req)
+	from := strconv.FormatInt(timeRange.From.UnixNano()/int64(time.Millisecond), 10)
+	to := strconv.FormatInt(timeRange.To.UnixNano()/int64(time.Millisecond), 10)
+
+	ms := client.MultiSearch()
+	b := ms.Search(tsdb.Interval{})
+	b.Size(0)
+	filters := b.Query().Bool().Filter()
+	filters.AddDateRangeFilter(client.GetTimeField(), to, from, es.DateFormatEpochMS)
+	if query := params.Get("query"); query != "" {
+		filters.AddQueryStringFilter(query, true)
+	}
+	b.Agg().Terms("1", field, func(a *es.TermsAggregation, _ es.AggBuilder) {
+		a.Size = size
+		if orderBy == "doc_count" {
+			a.Order["_count"] = order
+		} else if client.GetFlavor() == es.Elasticsearch && client.GetVersion().Major() < 6 {
+			a.Order["_term"] = order
+		} else {
+			a.Order["_key"] = order
+		}
+	})
+
+	msReq, err := ms.Build()
+	if err != nil {
+		writeResourceError(rw, http.StatusInternalServerError, err)
+		return
+	}
+	res, err := client.ExecuteMultisearch(msReq)
+	if err != nil {
+		writeResourceError(rw, http.StatusInternalServerError, err)
+		return
+	}
+
+	terms := make([]termResource, 0)
+	if len(res.Responses) > 0 {
+		if res.Responses[0].Error != nil {
+			writeResourceError(rw, http.StatusInternalServerError, getErrorFromOpenSearchResponse(res.Responses[0]))
+			return
+		}
+		buckets := utils.NewJsonFromAny(res.Responses[0].Aggregations).GetPath("1", "buckets").MustArray()
+		for _, b := range buckets {
+			bucket := utils.NewJsonFromAny(b)
+			key := bucket.Get("key").Interface()
+			text := bucket.Get("key_as_string").MustString(fmt.Sprint(key))
+			terms = append(terms, termResource{Text: text, Value: key})
+		}
+	}
+
+	writeResourceResponse(rw, terms)
+}
+
+// handleIndices returns the names of the indices matching the index pattern
+func (ds *OpenSearchDatasource) handleIndices(rw http.ResponseWriter, req *http.Request) {
+	client, err := ds.newResourceClient(req)
+	if err != nil {
+		writeResourceError(rw, http.StatusBadRequest, err)
+		return
+	}
+
+	mapping, err := client.GetMapping()
+	if err != nil {
+		writeResourceError(rw, http.StatusInternalServerError, err)
+		return
+	}
+
+	indices := make([]string, 0, len(mapping))
+	for index := range mapping {
+		indices = append(indices, index)
+	}
+	sort.Strings(indices)
+
+	writeResourceResponse(rw, indices)
+}
+
+// handleVersion returns the flavor and version reported by the cluster
+func (ds *OpenSearchDatasource) handleVersion(rw http.ResponseWriter, req *http.Request) {
+	client, err := ds.newResourceClient(req)
+	if err != nil {
+		writeResourceError(rw, http.StatusBadRequest, err)
+		return
+	}
+
+	info, err := client.GetClusterInfo()
+	if err != nil {
+		writeResourceError(rw, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeResourceResponse(rw, versionResource{Flavor: info.GetFlavor(), Version: info.Version.Number})
+}
+
+// collectMappingFields adds the fields of the mapping properties to fields, including the properties
+// of object and nested fields and the multi-fields of a field, e.g. `message.keyword`
+func collectMappingFields(properties map[string]interface{}, prefix string, fields map[string]string) {
+	for name, p := range properties {
+		if metaFields[name] {
+			continue
+		}
+		property, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		fieldName := prefix + name
+		if fieldType, ok := property["type"].(string); ok {
+			fields[fieldName] = fieldType
+		}
+		if nested, ok := property["properties"].(map[string]interface{}); ok {
+			collectMappingFields(nested, fieldName+".", fields)
+		}
+		if multiFields, ok := property["fields"].(map[string]interface{}); ok {
+			collectMappingFields(multiFields, fieldName+".", fields)
+		}
+	}
+}
+
+func (ds *OpenSearchDatasource) newResourceClient(req *http.Request) (es.Client, error) {
+	timeRange := getResourceTimeRange(req)
+	return es.NewClient(req.Context(), ds.dsInfo, &timeRange)
+}
+
+// getResourceTimeRange reads the time range from the `from` and `to` parameters in epoch milliseconds
+func getResourceTimeRange(req *http.Request) backend.TimeRange {
+	now := time.Now()
+	timeRange := backend.TimeRange{From: now.Add(-defaultResourceTimeRange), To: now}
+
+	params := req.URL.Query()
+	if from, err := strconv.ParseInt(params.Get("from"), 10, 64); err == nil {
+		timeRange.From = time.UnixMilli(from)
+	}
+	if to, err := strconv.ParseInt(params.Get("to"), 10, 64); err == nil {
+		timeRange.To = time.UnixMilli(to)
+	}
+
+	return timeRange
+}
+
+func writeResourceResponse(rw http.ResponseWriter, v interface{}) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		writeResourceError(rw, http.StatusInternalServerError, err)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	_, _ = rw.Write(body)
+}
+
+func writeResourceError(rw http.ResponseWriter, status int, err error) {
+	body, _ := json.Marshal(map[string]string{"message": err.Error()})
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	_, _ = rw.Write(body)
+}
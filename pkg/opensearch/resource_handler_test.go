@@ -0,0 +1,199 @@
+package opensearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	es "github.com/grafana/opensearch-datasource/pkg/opensearch/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCallResource(t *testing.T) {
+	newResourceFakeClient := func() *fakeClient {
+		c := newFakeClient(es.OpenSearch, "2.5.0")
+		c.clusterInfo = &es.ClusterInfo{Version: es.ClusterVersion{Number: "2.5.0", Distribution: "opensearch"}}
+		c.mapping = map[string]interface{}{
+			"metrics-2023.01.02": map[string]interface{}{
+				"mappings": map[string]interface{}{
+					"properties": map[string]interface{}{
+						"@timestamp": map[string]interface{}{"type": "date"},
+						"_id":        map[string]interface{}{"type": "keyword"},
+						"message": map[string]interface{}{
+							"type": "text",
+							"fields": map[string]interface{}{
+								"keyword": map[string]interface{}{"type": "keyword"},
+							},
+						},
+						"host": map[string]interface{}{
+							"properties": map[string]interface{}{
+								"cpu": map[string]interface{}{"type": "float"},
+							},
+						},
+					},
+				},
+			},
+			"metrics-2023.01.01": map[string]interface{}{
+				"mappings": map[string]interface{}{
+					"doc": map[string]interface{}{
+						"properties": map[string]interface{}{
+							"bytes": map[string]interface{}{"type": "long"},
+						},
+					},
+				},
+			},
+		}
+		return c
+	}
+
+	t.Run("Returns all fields", func(t *testing.T) {
+		res := callResourceForTest(t, newResourceFakeClient(), "fields")
+		require.Equal(t, http.StatusOK, res.Status)
+		assert.JSONEq(t, `[
+			{ "text": "@timestamp", "type": "date" },
+			{ "text": "bytes", "type": "long" },
+			{ "text": "host.cpu", "type": "float" },
+			{ "text": "message", "type": "text" },
+			{ "text": "message.keyword", "type": "keyword" }
+		]`, string(res.Body))
+	})
+
+	t.Run("Filters fields by type group", func(t *testing.T) {
+		res := callResourceForTest(t, newResourceFakeClient(), "fields?type=number")
+		require.Equal(t, http.StatusOK, res.Status)
+		assert.JSONEq(t, `[
+			{ "text": "bytes", "type": "long" },
+			{ "text": "host.cpu", "type": "float" }
+		]`, string(res.Body))
+
+		res = callResourceForTest(t, newResourceFakeClient(), "fields?type=keyword")
+		require.Equal(t, http.StatusOK, res.Status)
+		assert.JSONEq(t, `[{ "text": "message.keyword", "type": "keyword" }]`, string(res.Body))
+	})
+
+	t.Run("Returns the mapping error", func(t *testing.T) {
+		c := newResourceFakeClient()
+		c.requestError = errors.New("connection refused")
+		res := callResourceForTest(t, c, "fields")
+		assert.Equal(t, http.StatusInternalServerError, res.Status)
+		assert.JSONEq(t, `{ "message": "connection refused" }`, string(res.Body))
+	})
+
+	t.Run("Returns terms", func(t *testing.T) {
+		c := newResourceFakeClient()
+		c.multiSearchResponse = &es.MultiSearchResponse{
+			Responses: []*es.SearchResponse{
+				{
+					Aggregations: map[string]interface{}{
+						"1": map[string]interface{}{
+							"buckets": []interface{}{
+								map[string]interface{}{"key": "server1", "doc_count": 10},
+								map[string]interface{}{"key": 1672531200000, "key_as_string": "2023-01-01", "doc_count": 5},
+							},
+						},
+					},
+				},
+			},
+		}
+		res := callResourceForTest(t, c, "terms?field=host&query=level:error&size=10&orderBy=doc_count&from=1672531200000&to=1672617600000")
+		require.Equal(t, http.StatusOK, res.Status)
+		assert.JSONEq(t, `[
+			{ "text": "server1", "value": "server1" },
+			{ "text": "2023-01-01", "value": 1672531200000 }
+		]`, string(res.Body))
+
+		require.Len(t, c.multisearchRequests, 1)
+		sr := c.multisearchRequests[0].Requests[0]
+		assert.Equal(t, 0, sr.Size)
+
+		filters := sr.Query.Bool.Filters
+		require.Len(t, filters, 2)
+		rangeFilter := filters[0].(*es.RangeFilter)
+		assert.Equal(t, "@timestamp", rangeFilter.Key)
+		assert.Equal(t, "1672531200000", rangeFilter.Gte)
+		assert.Equal(t, "1672617600000", rangeFilter.Lte)
+		assert.Equal(t, "level:error", filters[1].(*es.QueryStringFilter).Query)
+
+		agg := sr.Aggs[0].Aggregation.Aggregation.(*es.TermsAggregation)
+		assert.Equal(t, "host", agg.Field)
+		assert.Equal(t, 10, agg.Size)
+		assert.Equal(t, "desc", agg.Order["_count"])
+	})
+
+	t.Run("Orders terms by key", func(t *testing.T) {
+		c := newResourceFakeClient()
+		res := callResourceForTest(t, c, "terms?field=host")
+		require.Equal(t, http.StatusOK, res.Status)
+		assert.JSONEq(t, `[]`, string(res.Body))
+
+		agg := c.multisearchRequests[0].Requests[0].Aggs[0].Aggregation.Aggregation.(*es.TermsAggregation)
+		assert.Equal(t, defaultTermsSize, agg.Size)
+		assert.Equal(t, "asc", agg.Order["_key"])
+	})
+
+	t.Run("Validates terms parameters", func(t *testing.T) {
+		res := callResourceForTest(t, newResourceFakeClient(), "terms")
+		assert.Equal(t, http.StatusBadRequest, res.Status)
+		assert.JSONEq(t, `{ "message": "field is required" }`, string(res.Body))
+
+		res = callResourceForTest(t, newResourceFakeClient(), "terms?field=host&size=-1")
+		assert.Equal(t, http.StatusBadRequest, res.Status)
+
+		res = callResourceForTest(t, newResourceFakeClient(), "terms?field=host&order=up")
+		assert.Equal(t, http.StatusBadRequest, res.Status)
+	})
+
+	t.Run("Returns indices", func(t *testing.T) {
+		res := callResourceForTest(t, newResourceFakeClient(), "indices")
+		require.Equal(t, http.StatusOK, res.Status)
+		assert.JSONEq(t, `["metrics-2023.01.01", "metrics-2023.01.02"]`, string(res.Body))
+	})
+
+	t.Run("Returns version", func(t *testing.T) {
+		res := callResourceForTest(t, newResourceFakeClient(), "version")
+		require.Equal(t, http.StatusOK, res.Status)
+		assert.JSONEq(t, `{ "flavor": "opensearch", "version": "2.5.0" }`, string(res.Body))
+	})
+}
+
+type fakeResourceSender struct {
+	response *backend.CallResourceResponse
+}
+
+func (s *fakeResourceSender) Send(res *backend.CallResourceResponse) error {
+	s.response = res
+	return nil
+}
+
+func callResourceForTest(t *testing.T, c *fakeClient, path string) *backend.CallResourceResponse {
+	t.Helper()
+
+	originalNewClient := es.NewClient
+	es.NewClient = func(ctx context.Context, ds *es.DatasourceInfo, timeRange *backend.TimeRange) (es.Client, error) {
+		return c, nil
+	}
+	t.Cleanup(func() {
+		es.NewClient = originalNewClient
+	})
+
+	instance, err := NewOpenSearchDatasource(backend.DataSourceInstanceSettings{
+		JSONData: json.RawMessage(`{ "version": "2.5.0", "timeField": "@timestamp", "database": "[metrics-]YYYY.MM.DD" }`),
+	})
+	require.NoError(t, err)
+
+	resourcePath, _, _ := strings.Cut(path, "?")
+	sender := &fakeResourceSender{}
+	err = instance.(*OpenSearchDatasource).CallResource(context.Background(), &backend.CallResourceRequest{
+		Method: http.MethodGet,
+		Path:   resourcePath,
+		URL:    "/api/datasources/1/resources/" + path,
+	}, sender)
+	require.NoError(t, err)
+	require.NotNil(t, sender.response)
+	return sender.response
+}
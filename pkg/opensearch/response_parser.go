@@ -1,11 +1,15 @@
 package opensearch
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
+	"math"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	simplejson "github.com/bitly/go-simplejson"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
@@ -18,28 +22,64 @@ import (
 
 const (
 	// Metric types
-	countType         = "count"
-	percentilesType   = "percentiles"
-	extendedStatsType = "extended_stats"
+	countType           = "count"
+	percentilesType     = "percentiles"
+	extendedStatsType   = "extended_stats"
+	statsType           = "stats"
+	percentileRanksType = "percentile_ranks"
+	weightedAvgType     = "weighted_avg"
+	boxplotType         = "boxplot"
+	stringStatsType     = "string_stats"
+	topMetricsType      = "top_metrics"
+	geoBoundsType       = "geo_bounds"
+	geoCentroidType     = "geo_centroid"
+	rawDocumentType     = "raw_document"
+	rawDataType         = "raw_data"
+	logsType            = "logs"
+	// Pipeline types
+	bucketSelectorType    = "bucket_selector"
+	bucketSortType        = "bucket_sort"
+	percentilesBucketType = "percentiles_bucket"
+	statsBucketType       = "stats_bucket"
 	// Bucket types
-	dateHistType    = "date_histogram"
-	histogramType   = "histogram"
-	filtersType     = "filters"
-	termsType       = "terms"
-	geohashGridType = "geohash_grid"
+	dateHistType         = "date_histogram"
+	histogramType        = "histogram"
+	filtersType          = "filters"
+	termsType            = "terms"
+	geohashGridType      = "geohash_grid"
+	geotileGridType      = "geotile_grid"
+	geohexGridType       = "geohex_grid"
+	autoDateHistType     = "auto_date_histogram"
+	rangeType            = "range"
+	dateRangeType        = "date_range"
+	ipRangeType          = "ip_range"
+	compositeType        = "composite"
+	multiTermsType       = "multi_terms"
+	rareTermsType        = "rare_terms"
+	significantTermsType = "significant_terms"
+)
+
+const defaultDocumentSize = 500
+
+const (
+	defaultAutoDateHistogramBuckets = 10
+	defaultCompositePageSize        = 1000
+	defaultCompositeMaxBuckets      = 10000
 )
 
 type responseParser struct {
-	Responses []*es.SearchResponse
-	Targets   []*Query
-	DebugInfo *es.SearchDebugInfo
+	Responses        []*es.SearchResponse
+	Targets          []*Query
+	DebugInfo        *es.SearchDebugInfo
+	ConfiguredFields ConfiguredFields
 }
 
-var newResponseParser = func(responses []*es.SearchResponse, targets []*Query, debugInfo *es.SearchDebugInfo) *responseParser {
+var newResponseParser = func(responses []*es.SearchResponse, targets []*Query, debugInfo *es.SearchDebugInfo, configuredFields ConfiguredFields) *responseParser {
 	return &responseParser{
-		Responses: responses,
-		Targets:   targets,
-		DebugInfo: debugInfo,
+		Responses:        responses,
+		Targets:          targets,
+		DebugInfo:        debugInfo,
+		ConfiguredFields: configuredFields,
 	}
 }
 
@@ -60,7 +100,8 @@ func (rp *responseParser) getTimeSeries() (*backend.QueryDataResponse, error) {
 
 		if res.Error != nil {
 			result.Responses[target.RefID] = backend.DataResponse{
-				Error: getErrorFromOpenSearchResponse(res),
+				Error:  getErrorFromOpenSearchResponse(res),
+				Status: responseErrorStatus(res.Status),
 				Frames: []*data.Frame{
 					{
 						Meta: &data.FrameMeta{
@@ -75,6 +116,19 @@ func (rp *responseParser) getTimeSeries() (*backend.QueryDataResponse, error) {
 		queryRes := backend.DataResponse{
 			Frames: data.Frames{},
 		}
+
+		if target.IsLogsQuery {
+			queryRes.Frames = append(queryRes.Frames, rp.processLogsResponse(res))
+			result.Responses[target.RefID] = queryRes
+			continue
+		}
+
+		if isDocumentQuery(target) {
+			queryRes.Frames = append(queryRes.Frames, rp.processDocumentResponse(res))
+			result.Responses[target.RefID] = queryRes
+			continue
+		}
+
 		// queryRes.Meta = debugInfo
 		props := make(map[string]string)
 		table := tsdb.Table{
@@ -83,14 +137,19 @@ func (rp *responseParser) getTimeSeries() (*backend.QueryDataResponse, error) {
 		}
 		err := rp.processBuckets(res.Aggregations, target, &queryRes.Frames, &table, props, 0)
 		if err != nil {
-			return nil, err
+			// a response that can't be parsed only fails its own query
+			result.Responses[target.RefID] = backend.DataResponse{
+				Error:  err,
+				Status: backend.StatusInternal,
+			}
+			continue
 		}
 		rp.nameSeries(&queryRes.Frames, target)
 		rp.trimDatapoints(&queryRes.Frames, target)
 
-		// if len(table.Rows) > 0 {
-		// 	queryRes.Tables = append(queryRes.Tables, &table)
-		// }
+		if len(table.Rows) > 0 {
+			queryRes.Frames = append(queryRes.Frames, tableToFrame(table, target))
+		}
 
 		result.Responses[target.RefID] = queryRes
 	}
@@ -114,11 +173,17 @@ func (rp *responseParser) processBuckets(aggs map[string]interface{}, target *Qu
 			continue
 		}
 
+		if isRangeAgg(aggDef.Type) {
+			normalizeRangeBuckets(esAgg, aggDef)
+		}
+
 		if depth == maxDepth {
-			if aggDef.Type == dateHistType {
+			if aggDef.Type == dateHistType || aggDef.Type == autoDateHistType {
+				sortBucketsByKey(esAgg)
 				err = rp.processMetrics(esAgg, target, series, props)
+				rp.processSiblingPipelines(utils.NewJsonFromAny(aggs), esAgg, target, series, props)
 			} else {
-				err = rp.processAggregationDocs(esAgg, aggDef, target, table, props)
+				err = rp.processAggregationDocs(utils.NewJsonFromAny(aggs), esAgg, aggDef, target, table, props)
 			}
 			if err != nil {
 				return err
@@ -132,14 +197,22 @@ func (rp *responseParser) processBuckets(aggs map[string]interface{}, target *Qu
 					newProps[k] = v
 				}
 
-				if key, err := bucket.Get("key").String(); err == nil {
-					newProps[aggDef.Field] = key
-				} else if key, err := bucket.Get("key").Int64(); err == nil {
-					newProps[aggDef.Field] = strconv.FormatInt(key, 10)
-				}
+				if isCompoundKeyAgg(aggDef.Type) {
+					// each field of the key becomes a label of the series
+					keys := getCompoundKey(bucket, aggDef)
+					for i, field := range getKeyFields(aggDef) {
+						newProps[field] = keys[i]
+					}
+				} else {
+					if key, err := bucket.Get("key").String(); err == nil {
+						newProps[aggDef.Field] = key
+					} else if key, err := bucket.Get("key").Int64(); err == nil {
+						newProps[aggDef.Field] = strconv.FormatInt(key, 10)
+					}
 
-				if key, err := bucket.Get("key_as_string").String(); err == nil {
-					newProps[aggDef.Field] = key
+					if key, err := bucket.Get("key_as_string").String(); err == nil {
+						newProps[aggDef.Field] = key
+					}
 				}
 				err = rp.processBuckets(bucket.MustMap(), target, series, table, newProps, depth+1)
 				if err != nil {
@@ -176,7 +249,7 @@ func (rp *responseParser) processBuckets(aggs map[string]interface{}, target *Qu
 
 func (rp *responseParser) processMetrics(esAgg *simplejson.Json, target *Query, frames *data.Frames, props map[string]string) error {
 	for _, metric := range target.Metrics {
-		if metric.Hide {
+		if metric.Hide || !addsBucketValues(metric.Type) {
 			continue
 		}
 
@@ -289,6 +362,30 @@ func (rp *responseParser) processMetrics(esAgg *simplejson.Json, target *Query,
 				}
 				*frames = append(*frames, newFrame)
 			}
+		case statsType, percentileRanksType, boxplotType, stringStatsType, topMetricsType, geoBoundsType, geoCentroidType:
+			buckets := esAgg.Get("buckets").MustArray()
+
+			for _, metricValue := range getMetricValues(metric, buckets) {
+				newFrame := data.NewFrame(target.Alias,
+					data.NewFieldFromFieldType(data.FieldTypeNullableTime, len(buckets)),
+					data.NewFieldFromFieldType(data.FieldTypeNullableFloat64, len(buckets)),
+				)
+				valueField := newFrame.Fields[1]
+				valueField.Labels = data.Labels{}
+
+				for k, v := range props {
+					valueField.Labels[k] = v
+				}
+				valueField.Labels["metric"] = metricValue.name
+				valueField.Labels["field"] = metricValue.field
+
+				for i, v := range buckets {
+					bucket := utils.NewJsonFromAny(v)
+					key := castToNullFloat(bucket.Get("key"))
+					setFrameRow(newFrame, i, key, metricValue.get(bucket))
+				}
+				*frames = append(*frames, newFrame)
+			}
 		default:
 			buckets := esAgg.Get("buckets").MustArray()
 
@@ -328,7 +425,282 @@ func (rp *responseParser) processMetrics(esAgg *simplejson.Json, target *Query,
 	return nil
 }
 
-func (rp *responseParser) processAggregationDocs(esAgg *simplejson.Json, aggDef *BucketAgg, target *Query, table *tsdb.Table, props map[string]string) error {
+// processSiblingPipelines adds a series per value of the sibling pipeline aggregations of a date histogram,
+// which are drawn as a constant over the buckets of the histogram
+func (rp *responseParser) processSiblingPipelines(aggs *simplejson.Json, esAgg *simplejson.Json, target *Query, frames *data.Frames, props map[string]string) {
+	buckets := esAgg.Get("buckets").MustArray()
+	if len(buckets) == 0 {
+		return
+	}
+
+	keys := []null.Float{castToNullFloat(utils.NewJsonFromAny(buckets[0]).Get("key"))}
+	if len(buckets) > 1 {
+		keys = append(keys, castToNullFloat(utils.NewJsonFromAny(buckets[len(buckets)-1]).Get("key")))
+	}
+
+	for _, metric := range target.Metrics {
+		if metric.Hide || !isSiblingPipelineAgg(metric.Type) {
+			continue
+		}
+
+		for _, metricValue := range getSiblingPipelineValues(metric, target, aggs) {
+			newFrame := data.NewFrame(target.Alias,
+				data.NewFieldFromFieldType(data.FieldTypeNullableTime, len(keys)),
+				data.NewFieldFromFieldType(data.FieldTypeNullableFloat64, len(keys)),
+			)
+			valueField := newFrame.Fields[1]
+			valueField.Labels = data.Labels{}
+
+			for k, v := range props {
+				valueField.Labels[k] = v
+			}
+			valueField.Labels["metric"] = metricValue.name
+			valueField.Labels["field"] = metricValue.field
+
+			value := metricValue.get(aggs)
+			for i, key := range keys {
+				setFrameRow(newFrame, i, key, value)
+			}
+			*frames = append(*frames, newFrame)
+		}
+	}
+}
+
+// getSiblingPipelineValues returns the values of a sibling pipeline aggregation, which are read from the
+// aggregations next to the bucket aggregation it aggregates
+func getSiblingPipelineValues(metric *MetricAgg, target *Query, aggs *simplejson.Json) []metricValue {
+	metricValues := getMetricValues(metric, []interface{}{aggs.Interface()})
+	if len(metricValues) == 0 {
+		return []metricValue{{
+			name:  metric.Type,
+			field: metric.PipelineAggregate,
+			get: func(aggs *simplejson.Json) null.Float {
+				return castToNullFloat(aggs.GetPath(metric.ID, "value"))
+			},
+		}}
+	}
+
+	// the values of multi-value pipelines are named after the metric they aggregate
+	for _, m := range target.Metrics {
+		if m.ID == metric.PipelineAggregate {
+			for i := range metricValues {
+				metricValues[i].field = describeMetric(m.Type, m.Field)
+			}
+		}
+	}
+	return metricValues
+}
+
+// getCompoundKey returns the values of the fields of the key of a composite or multi_terms bucket as
+// strings, the key of a composite bucket is an object and the key of a multi_terms bucket is a list
+func getCompoundKey(bucket *simplejson.Json, aggDef *BucketAgg) []string {
+	fields := getKeyFields(aggDef)
+	keys := make([]string, len(fields))
+	for i, field := range fields {
+		key := bucket.Get("key").GetIndex(i)
+		if aggDef.Type == compositeType {
+			key = bucket.GetPath("key", field)
+		}
+
+		if s, err := key.String(); err == nil {
+			keys[i] = s
+		} else if key.Interface() != nil {
+			keys[i] = fmt.Sprint(key.Interface())
+		}
+	}
+	return keys
+}
+
+// decodeGeoGridKey returns the latitude and longitude of the centre of the cell of a geohash_grid bucket,
+// keyed by its geohash, or of a geotile_grid bucket, keyed by its "zoom/x/y" map tile
+func decodeGeoGridKey(bucketAggType string, key string) (null.Float, null.Float) {
+	invalid := null.NewFloat(0, false)
+
+	if bucketAggType == geotileGridType {
+		parts := strings.Split(key, "/")
+		if len(parts) != 3 {
+			return invalid, invalid
+		}
+		zoom, zoomErr := strconv.Atoi(parts[0])
+		x, xErr := strconv.Atoi(parts[1])
+		y, yErr := strconv.Atoi(parts[2])
+		if zoomErr != nil || xErr != nil || yErr != nil {
+			return invalid, invalid
+		}
+
+		tiles := math.Exp2(float64(zoom))
+		lon := (float64(x)+0.5)/tiles*360 - 180
+		lat := math.Atan(math.Sinh(math.Pi*(1-2*(float64(y)+0.5)/tiles))) * 180 / math.Pi
+		return null.FloatFrom(lat), null.FloatFrom(lon)
+	}
+
+	// each character of a geohash halves the cell five times, alternately along the longitude and the latitude
+	const geohashAlphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+	if key == "" {
+		return invalid, invalid
+	}
+	latRange := [2]float64{-90, 90}
+	lonRange := [2]float64{-180, 180}
+	even := true
+	for _, c := range key {
+		index := strings.IndexRune(geohashAlphabet, c)
+		if index < 0 {
+			return invalid, invalid
+		}
+		for bit := 4; bit >= 0; bit-- {
+			r := &latRange
+			if even {
+				r = &lonRange
+			}
+			mid := (r[0] + r[1]) / 2
+			if index&(1<<bit) != 0 {
+				r[0] = mid
+			} else {
+				r[1] = mid
+			}
+			even = !even
+		}
+	}
+	return null.FloatFrom((latRange[0] + latRange[1]) / 2), null.FloatFrom((lonRange[0] + lonRange[1]) / 2)
+}
+
+// normalizeRangeBuckets turns the buckets of a keyed range aggregation into a list of buckets with
+// their key, in the order of the ranges of the aggregation, like the buckets of an unkeyed one
+func normalizeRangeBuckets(esAgg *simplejson.Json, aggDef *BucketAgg) {
+	keyedBuckets, err := esAgg.Get("buckets").Map()
+	if err != nil {
+		return
+	}
+
+	keys := make([]string, 0, len(keyedBuckets))
+	found := make(map[string]bool)
+	for _, r := range aggDef.Settings.Get("ranges").MustArray() {
+		key := getRangeKey(aggDef, utils.NewJsonFromAny(r))
+		if _, ok := keyedBuckets[key]; ok && !found[key] {
+			keys = append(keys, key)
+			found[key] = true
+		}
+	}
+
+	// the keys of date ranges and formatted ranges without a key can't be derived from the settings, their
+	// buckets are ordered by their bounds instead
+	remainingKeys := make([]string, 0)
+	for key := range keyedBuckets {
+		if !found[key] {
+			remainingKeys = append(remainingKeys, key)
+		}
+	}
+	sort.Slice(remainingKeys, func(i, j int) bool {
+		iBucket := utils.NewJsonFromAny(keyedBuckets[remainingKeys[i]])
+		jBucket := utils.NewJsonFromAny(keyedBuckets[remainingKeys[j]])
+		for _, bound := range []string{"from", "to"} {
+			iBound, jBound := getBucketBound(iBucket, bound), getBucketBound(jBucket, bound)
+			if iBound != jBound {
+				return iBound < jBound
+			}
+		}
+		return remainingKeys[i] < remainingKeys[j]
+	})
+	keys = append(keys, remainingKeys...)
+
+	buckets := make([]interface{}, 0, len(keys))
+	for _, key := range keys {
+		bucket := utils.NewJsonFromAny(keyedBuckets[key])
+		bucket.Set("key", key)
+		buckets = append(buckets, bucket.Interface())
+	}
+	esAgg.Set("buckets", buckets)
+}
+
+// getRangeKey returns the key of the bucket of a range, which OpenSearch derives from the bounds of the range
+// when it has no key. It is empty when the key can't be derived from the settings.
+func getRangeKey(aggDef *BucketAgg, r *simplejson.Json) string {
+	if key := r.Get("key").MustString(); key != "" {
+		return key
+	}
+
+	switch aggDef.Type {
+	case ipRangeType:
+		if mask := r.Get("mask").MustString(); mask != "" {
+			return mask
+		}
+		bounds := make([]string, 0, 2)
+		for _, bound := range []interface{}{getRangeBound(r.Get("from")), getRangeBound(r.Get("to"))} {
+			if bound == nil {
+				bounds = append(bounds, "*")
+			} else {
+				bounds = append(bounds, fmt.Sprint(bound))
+			}
+		}
+		return strings.Join(bounds, "-")
+	case rangeType:
+		if aggDef.Settings.Get("format").MustString() != "" {
+			return ""
+		}
+		bounds := make([]string, 0, 2)
+		for _, bound := range []interface{}{getRangeBound(r.Get("from")), getRangeBound(r.Get("to"))} {
+			if bound == nil {
+				bounds = append(bounds, "*")
+				continue
+			}
+			value, err := strconv.ParseFloat(fmt.Sprint(bound), 64)
+			if err != nil {
+				return ""
+			}
+			bounds = append(bounds, formatJavaDouble(value))
+		}
+		return strings.Join(bounds, "-")
+	}
+
+	return ""
+}
+
+// formatJavaDouble formats a number like Java's Double.toString, which formats the bounds in the keys of
+// numeric ranges
+func formatJavaDouble(value float64) string {
+	if abs := math.Abs(value); value == 0 || (abs >= 1e-3 && abs < 1e7) {
+		s := strconv.FormatFloat(value, 'f', -1, 64)
+		if !strings.Contains(s, ".") {
+			s += ".0"
+		}
+		return s
+	}
+
+	s := strconv.FormatFloat(value, 'E', -1, 64)
+	mantissa, exponent, _ := strings.Cut(s, "E")
+	if !strings.Contains(mantissa, ".") {
+		mantissa += ".0"
+	}
+	exp, _ := strconv.Atoi(exponent)
+	return mantissa + "E" + strconv.Itoa(exp)
+}
+
+// getBucketBound returns the from or to bound of the bucket of a range, an unbounded from is the lowest
+// bound and an unbounded to the highest
+func getBucketBound(bucket *simplejson.Json, bound string) float64 {
+	if value := castToNullFloat(bucket.Get(bound)); value.Valid {
+		return value.Float64
+	}
+	if bound == "from" {
+		return math.Inf(-1)
+	}
+	return math.Inf(1)
+}
+
+// sortBucketsByKey sorts the buckets of a date histogram by time, since bucket_sort can reorder them
+func sortBucketsByKey(esAgg *simplejson.Json) {
+	buckets := esAgg.Get("buckets").MustArray()
+	sort.SliceStable(buckets, func(i, j int) bool {
+		iKey := castToNullFloat(utils.NewJsonFromAny(buckets[i]).Get("key"))
+		jKey := castToNullFloat(utils.NewJsonFromAny(buckets[j]).Get("key"))
+		return iKey.Float64 < jKey.Float64
+	})
+	esAgg.Set("buckets", buckets)
+}
+
+// processAggregationDocs adds a row per bucket of the last bucket aggregation to the table. The sibling
+// pipelines of the bucket aggregation are read from aggs, and each of their values is repeated on every row.
+func (rp *responseParser) processAggregationDocs(aggs *simplejson.Json, esAgg *simplejson.Json, aggDef *BucketAgg, target *Query, table *tsdb.Table, props map[string]string) error {
 	propKeys := make([]string, 0)
 	for k := range props {
 		propKeys = append(propKeys, k)
@@ -339,7 +711,24 @@ func (rp *responseParser) processAggregationDocs(esAgg *simplejson.Json, aggDef
 		for _, propKey := range propKeys {
 			table.Columns = append(table.Columns, tsdb.TableColumn{Text: propKey})
 		}
-		table.Columns = append(table.Columns, tsdb.TableColumn{Text: aggDef.Field})
+		if isCompoundKeyAgg(aggDef.Type) {
+			for _, field := range getKeyFields(aggDef) {
+				table.Columns = append(table.Columns, tsdb.TableColumn{Text: field})
+			}
+		} else if aggDef.Type == filtersType {
+			table.Columns = append(table.Columns, tsdb.TableColumn{Text: "filter"})
+		} else {
+			table.Columns = append(table.Columns, tsdb.TableColumn{Text: aggDef.Field})
+		}
+	}
+
+	// the cells of a geo grid are placed on the map at the centroid of their documents when the query
+	// has a geo_centroid metric, otherwise at the centre of the cell
+	hasGeoCentroid := false
+	for _, metric := range target.Metrics {
+		if metric.Type == geoCentroidType && !metric.Hide {
+			hasGeoCentroid = true
+		}
 	}
 
 	addMetricValue := func(values *tsdb.RowValues, metricName string, value null.Float) {
@@ -356,7 +745,24 @@ func (rp *responseParser) processAggregationDocs(esAgg *simplejson.Json, aggDef
 		*values = append(*values, value)
 	}
 
-	for _, v := range esAgg.Get("buckets").MustArray() {
+	// the buckets of a filters aggregation are keyed by the name of their filter, which is the key of the row
+	buckets := esAgg.Get("buckets").MustArray()
+	if keyedBuckets, err := esAgg.Get("buckets").Map(); err == nil {
+		names := make([]string, 0, len(keyedBuckets))
+		for name := range keyedBuckets {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		buckets = make([]interface{}, 0, len(names))
+		for _, name := range names {
+			bucket := utils.NewJsonFromAny(keyedBuckets[name])
+			bucket.Set("key", name)
+			buckets = append(buckets, bucket.Interface())
+		}
+	}
+
+	for _, v := range buckets {
 		bucket := utils.NewJsonFromAny(v)
 		values := make(tsdb.RowValues, 0)
 
@@ -364,13 +770,36 @@ func (rp *responseParser) processAggregationDocs(esAgg *simplejson.Json, aggDef
 			values = append(values, props[propKey])
 		}
 
-		if key, err := bucket.Get("key").String(); err == nil {
+		if isCompoundKeyAgg(aggDef.Type) {
+			for _, key := range getCompoundKey(bucket, aggDef) {
+				values = append(values, key)
+			}
+		} else if key, err := bucket.Get("key").String(); err == nil {
 			values = append(values, key)
 		} else {
 			values = append(values, castToNullFloat(bucket.Get("key")))
 		}
 
+		// the h3 cells of a geohex grid can't be decoded without the h3 library, they are only placed on
+		// the map by a geo_centroid metric
+		if (aggDef.Type == geohashGridType || aggDef.Type == geotileGridType) && !hasGeoCentroid {
+			lat, lon := decodeGeoGridKey(aggDef.Type, bucket.Get("key").MustString())
+			addMetricValue(&values, rp.getMetricName("lat"), lat)
+			addMetricValue(&values, rp.getMetricName("lon"), lon)
+		}
+
+		// the score of a significant term is how much more frequent the term is in the buckets than in
+		// the background set of documents
+		if aggDef.Type == significantTermsType {
+			addMetricValue(&values, "Score", castToNullFloat(bucket.Get("score")))
+			addMetricValue(&values, "Background Count", castToNullFloat(bucket.Get("bg_count")))
+		}
+
 		for _, metric := range target.Metrics {
+			if metric.Hide || !addsBucketValues(metric.Type) {
+				continue
+			}
+
 			switch metric.Type {
 			case countType:
 				addMetricValue(&values, rp.getMetricName(metric.Type), castToNullFloat(bucket.Get("doc_count")))
@@ -397,8 +826,25 @@ func (rp *responseParser) processAggregationDocs(esAgg *simplejson.Json, aggDef
 						value = castToNullFloat(bucket.GetPath(metric.ID, statName))
 					}
 
-					addMetricValue(&values, rp.getMetricName(metric.Type), value)
-					break
+					addMetricValue(&values, rp.getMetricName(statName), value)
+				}
+			case percentilesType:
+				percentiles := bucket.GetPath(metric.ID, "values")
+				percentileKeys := make([]string, 0)
+				for k := range percentiles.MustMap() {
+					percentileKeys = append(percentileKeys, k)
+				}
+				sort.Strings(percentileKeys)
+				for _, percentileName := range percentileKeys {
+					addMetricValue(&values, "p"+percentileName+" "+metric.Field, castToNullFloat(percentiles.Get(percentileName)))
+				}
+			case statsType, percentileRanksType, boxplotType, stringStatsType, topMetricsType, geoBoundsType, geoCentroidType:
+				for _, metricValue := range getMetricValues(metric, []interface{}{v}) {
+					metricName := rp.getMetricName(metricValue.name)
+					if metricValue.field != "" {
+						metricName += " " + metricValue.field
+					}
+					addMetricValue(&values, metricName, metricValue.get(bucket))
 				}
 			default:
 				metricName := rp.getMetricName(metric.Type)
@@ -422,12 +868,80 @@ func (rp *responseParser) processAggregationDocs(esAgg *simplejson.Json, aggDef
 			}
 		}
 
+		for _, metric := range target.Metrics {
+			if metric.Hide || !isSiblingPipelineAgg(metric.Type) {
+				continue
+			}
+
+			// the columns are named after the metric the pipeline aggregates, like its series
+			aggregated := ""
+			for _, m := range target.Metrics {
+				if m.ID == metric.PipelineAggregate {
+					aggregated = describeMetric(m.Type, m.Field)
+				}
+			}
+			if aggregated == "" {
+				continue
+			}
+
+			for _, metricValue := range getSiblingPipelineValues(metric, target, aggs) {
+				addMetricValue(&values, rp.getMetricName(metricValue.name)+" "+aggregated, metricValue.get(aggs))
+			}
+		}
+
 		table.Rows = append(table.Rows, values)
 	}
 
 	return nil
 }
 
+// tableToFrame converts the rows of a bucket aggregation without a date histogram into a frame
+// with a string field per bucket key that is a string and a number field per metric
+func tableToFrame(table tsdb.Table, target *Query) *data.Frame {
+	fields := make([]*data.Field, len(table.Columns))
+	for i, column := range table.Columns {
+		if isStringColumn(table.Rows, i) {
+			values := make([]*string, len(table.Rows))
+			for j, row := range table.Rows {
+				if i >= len(row) {
+					continue
+				}
+				if value, ok := row[i].(null.Float); ok && !value.Valid {
+					continue
+				}
+				values[j] = toNullableString(row[i])
+			}
+			fields[i] = data.NewField(column.Text, nil, values)
+			continue
+		}
+
+		values := make([]*float64, len(table.Rows))
+		for j, row := range table.Rows {
+			if i >= len(row) {
+				continue
+			}
+			if value, ok := row[i].(null.Float); ok && value.Valid {
+				values[j] = &value.Float64
+			}
+		}
+		fields[i] = data.NewField(column.Text, nil, values)
+	}
+
+	return data.NewFrame(target.Alias, fields...)
+}
+
+func isStringColumn(rows []tsdb.RowValues, column int) bool {
+	for _, row := range rows {
+		if column >= len(row) {
+			continue
+		}
+		if _, ok := row[column].(null.Float); !ok {
+			return true
+		}
+	}
+	return false
+}
+
 func (rp *responseParser) trimDatapoints(frames *data.Frames, target *Query) {
 	var histogram *BucketAgg
 	for _, bucketAgg := range target.BucketAggs {
@@ -585,9 +1099,267 @@ func (rp *responseParser) getMetricName(metric string) string {
 		return text
 	}
 
+	if text, ok := metricValueNames[metric]; ok {
+		return text
+	}
+
 	return metric
 }
 
+func (rp *responseParser) processLogsResponse(res *es.SearchResponse) *data.Frame {
+	docs, propNames, searchWords := flattenHits(res.Hits)
+	fields := createDocumentFields(docs, propNames, rp.ConfiguredFields, true)
+
+	frame := data.NewFrame("", fields...)
+	frame.Meta = &data.FrameMeta{
+		PreferredVisualization: data.VisTypeLogs,
+		Custom: map[string]interface{}{
+			"searchWords": searchWords,
+		},
+	}
+	return frame
+}
+
+func (rp *responseParser) processDocumentResponse(res *es.SearchResponse) *data.Frame {
+	docs, propNames, _ := flattenHits(res.Hits)
+	fields := createDocumentFields(docs, propNames, rp.ConfiguredFields, false)
+
+	frame := data.NewFrame("", fields...)
+	frame.Meta = &data.FrameMeta{
+		PreferredVisualization: data.VisTypeTable,
+	}
+	return frame
+}
+
+var highlightPatternRegex = regexp.MustCompile(regexp.QuoteMeta(es.HighlightPreTag) + `(.*?)` + regexp.QuoteMeta(es.HighlightPostTag))
+
+// flattenHits flattens the _source of every hit so that each nested property ends up in its own
+// column. It returns the flattened documents, the sorted names of all properties found in any of
+// the documents and the sorted words that were highlighted by OpenSearch.
+func flattenHits(hits *es.SearchResponseHits) ([]map[string]interface{}, []string, []string) {
+	docs := make([]map[string]interface{}, 0)
+	propNames := make(map[string]bool)
+	searchWords := make(map[string]bool)
+
+	if hits == nil {
+		return docs, sortedKeys(propNames), sortedKeys(searchWords)
+	}
+
+	for _, hit := range hits.Hits {
+		doc := make(map[string]interface{})
+		for _, key := range []string{"_id", "_type", "_index"} {
+			if value, ok := hit[key]; ok && value != nil {
+				doc[key] = value
+			}
+		}
+
+		if source, ok := hit["_source"].(map[string]interface{}); ok {
+			doc["_source"] = source
+			for k, v := range utils.FlattenNestedObject(source) {
+				doc[k] = v
+			}
+		}
+
+		// doc value fields are returned as arrays and only fill in what is missing from _source
+		if fields, ok := hit["fields"].(map[string]interface{}); ok {
+			for k, v := range fields {
+				if _, exists := doc[k]; exists {
+					continue
+				}
+				if values, ok := v.([]interface{}); ok && len(values) == 1 {
+					v = values[0]
+				}
+				doc[k] = v
+			}
+		}
+
+		if highlight, ok := hit["highlight"].(map[string]interface{}); ok {
+			for _, fragments := range highlight {
+				fragmentList, ok := fragments.([]interface{})
+				if !ok {
+					continue
+				}
+				for _, fragment := range fragmentList {
+					text, ok := fragment.(string)
+					if !ok {
+						continue
+					}
+					for _, match := range highlightPatternRegex.FindAllStringSubmatch(text, -1) {
+						searchWords[match[1]] = true
+					}
+				}
+			}
+		}
+
+		for k := range doc {
+			propNames[k] = true
+		}
+		docs = append(docs, doc)
+	}
+
+	return docs, sortedKeys(propNames), sortedKeys(searchWords)
+}
+
+// createDocumentFields creates one typed field per property, with the configured time field first.
+// For logs the configured message field and a `level` field, which is what Grafana uses to detect
+// the log level, come right after the time field.
+func createDocumentFields(docs []map[string]interface{}, propNames []string, configuredFields ConfiguredFields, isLogsQuery bool) []*data.Field {
+	fields := make([]*data.Field, 0, len(propNames)+3)
+	added := make(map[string]bool)
+	filterable := true
+
+	if configuredFields.TimeField != "" {
+		timeField := data.NewFieldFromFieldType(data.FieldTypeNullableTime, len(docs))
+		timeField.Name = configuredFields.TimeField
+		timeField.Config = &data.FieldConfig{Filterable: &filterable}
+		for i, doc := range docs {
+			timeField.Set(i, parseTimeValue(doc[configuredFields.TimeField]))
+		}
+		fields = append(fields, timeField)
+		added[configuredFields.TimeField] = true
+	}
+
+	if isLogsQuery && configuredFields.LogMessageField != "" {
+		fields = append(fields, createStringField(configuredFields.LogMessageField, configuredFields.LogMessageField, docs))
+		added[configuredFields.LogMessageField] = true
+	}
+
+	if isLogsQuery && configuredFields.LogLevelField != "" {
+		fields = append(fields, createStringField("level", configuredFields.LogLevelField, docs))
+		added["level"] = true
+	}
+
+	for _, propName := range propNames {
+		if added[propName] {
+			continue
+		}
+		// Other than logs, each _source property is shown as a column so _source itself is left out
+		if !isLogsQuery && propName == "_source" {
+			continue
+		}
+
+		field := createTypedField(propName, docs)
+		field.Config = &data.FieldConfig{Filterable: &filterable}
+		fields = append(fields, field)
+	}
+
+	return fields
+}
+
+func createStringField(name, propName string, docs []map[string]interface{}) *data.Field {
+	field := data.NewFieldFromFieldType(data.FieldTypeNullableString, len(docs))
+	field.Name = name
+	for i, doc := range docs {
+		if value, ok := doc[propName]; ok && value != nil {
+			field.Set(i, toNullableString(value))
+		}
+	}
+	return field
+}
+
+// createTypedField creates a number or boolean field when all values of the property have that
+// type, and a string field otherwise
+func createTypedField(propName string, docs []map[string]interface{}) *data.Field {
+	fieldType := inferFieldType(propName, docs)
+	field := data.NewFieldFromFieldType(fieldType, len(docs))
+	field.Name = propName
+
+	for i, doc := range docs {
+		value, ok := doc[propName]
+		if !ok || value == nil {
+			continue
+		}
+		switch fieldType {
+		case data.FieldTypeNullableFloat64:
+			v := value.(float64)
+			field.Set(i, &v)
+		case data.FieldTypeNullableBool:
+			v := value.(bool)
+			field.Set(i, &v)
+		default:
+			field.Set(i, toNullableString(value))
+		}
+	}
+
+	return field
+}
+
+func inferFieldType(propName string, docs []map[string]interface{}) data.FieldType {
+	fieldType := data.FieldTypeUnknown
+	for _, doc := range docs {
+		var valueType data.FieldType
+		switch doc[propName].(type) {
+		case nil:
+			continue
+		case float64:
+			valueType = data.FieldTypeNullableFloat64
+		case bool:
+			valueType = data.FieldTypeNullableBool
+		default:
+			return data.FieldTypeNullableString
+		}
+
+		if fieldType != data.FieldTypeUnknown && fieldType != valueType {
+			return data.FieldTypeNullableString
+		}
+		fieldType = valueType
+	}
+
+	if fieldType == data.FieldTypeUnknown {
+		return data.FieldTypeNullableString
+	}
+	return fieldType
+}
+
+func toNullableString(value interface{}) *string {
+	if s, ok := value.(string); ok {
+		return &s
+	}
+
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	s := string(bytes)
+	return &s
+}
+
+var documentTimeLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999", pplTSFormat, pplDateFormat}
+
+// parseTimeValue parses a time from epoch milliseconds or one of the common date formats
+func parseTimeValue(value interface{}) *time.Time {
+	switch v := value.(type) {
+	case []interface{}:
+		if len(v) > 0 {
+			return parseTimeValue(v[0])
+		}
+	case float64:
+		t := time.UnixMilli(int64(v)).UTC()
+		return &t
+	case string:
+		if ms, err := strconv.ParseInt(v, 10, 64); err == nil {
+			t := time.UnixMilli(ms).UTC()
+			return &t
+		}
+		for _, layout := range documentTimeLayouts {
+			if t, err := time.Parse(layout, v); err == nil {
+				t = t.UTC()
+				return &t
+			}
+		}
+	}
+	return nil
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 func castToNullFloat(j *simplejson.Json) null.Float {
 	f, err := j.Float64()
 	if err == nil {
@@ -607,6 +1379,135 @@ func castToNullFloat(j *simplejson.Json) null.Float {
 	return null.NewFloat(0, false)
 }
 
+// metricValue is one of the values of a metric that returns several values, each of them is returned
+// as its own series or column
+type metricValue struct {
+	name  string
+	field string
+	get   func(bucket *simplejson.Json) null.Float
+}
+
+// getMetricValues returns the values of a stats, percentile_ranks, boxplot, string_stats, top_metrics,
+// geo_bounds or geo_centroid metric, or of a stats_bucket or percentiles_bucket pipeline. The values of
+// the stats metrics can be picked in the meta of the metric, like extended_stats.
+func getMetricValues(metric *MetricAgg, buckets []interface{}) []metricValue {
+	fixedValues := func(names []string) []metricValue {
+		meta := metric.Meta.MustMap()
+		values := make([]metricValue, 0, len(names))
+		for _, name := range names {
+			if len(meta) > 0 {
+				if enabled, ok := meta[name].(bool); !ok || !enabled {
+					continue
+				}
+			}
+
+			name := name
+			values = append(values, metricValue{
+				name:  name,
+				field: metric.Field,
+				get: func(bucket *simplejson.Json) null.Float {
+					return castToNullFloat(bucket.GetPath(metric.ID, name))
+				},
+			})
+		}
+		return values
+	}
+
+	// the keys of the values are read from the first bucket
+	keyedValues := func(prefix string) []metricValue {
+		if len(buckets) == 0 {
+			return nil
+		}
+
+		keyed := utils.NewJsonFromAny(buckets[0]).GetPath(metric.ID, "values").MustMap()
+		keys := make([]string, 0, len(keyed))
+		for k := range keyed {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		values := make([]metricValue, 0, len(keys))
+		for _, key := range keys {
+			key := key
+			values = append(values, metricValue{
+				name:  prefix + key,
+				field: metric.Field,
+				get: func(bucket *simplejson.Json) null.Float {
+					return castToNullFloat(bucket.GetPath(metric.ID, "values", key))
+				},
+			})
+		}
+		return values
+	}
+
+	switch metric.Type {
+	case statsType:
+		return fixedValues(statsValues)
+	case boxplotType:
+		return fixedValues(boxplotValues)
+	case stringStatsType:
+		return fixedValues(stringStatsValues)
+	case statsBucketType:
+		return fixedValues(statsValues)
+	case percentileRanksType:
+		return keyedValues("rank ")
+	case percentilesBucketType:
+		return keyedValues("p")
+	case topMetricsType:
+		fields := metric.Settings.Get("metrics").MustStringArray()
+		values := make([]metricValue, 0, len(fields))
+		for _, field := range fields {
+			field := field
+			values = append(values, metricValue{
+				name:  topMetricsType,
+				field: field,
+				get: func(bucket *simplejson.Json) null.Float {
+					return castToNullFloat(bucket.GetPath(metric.ID, "top").GetIndex(0).GetPath("metrics", field))
+				},
+			})
+		}
+		return values
+	case geoBoundsType:
+		values := make([]metricValue, 0, 4)
+		for _, corner := range []string{"top_left", "bottom_right"} {
+			for _, coordinate := range []string{"lat", "lon"} {
+				corner, coordinate := corner, coordinate
+				values = append(values, metricValue{
+					name:  corner + "_" + coordinate,
+					field: metric.Field,
+					get: func(bucket *simplejson.Json) null.Float {
+						return castToNullFloat(bucket.GetPath(metric.ID, "bounds", corner, coordinate))
+					},
+				})
+			}
+		}
+		return values
+	case geoCentroidType:
+		// the centroid has no field in its names, so that maps find its latitude and longitude
+		values := make([]metricValue, 0, 2)
+		for _, coordinate := range []string{"lat", "lon"} {
+			coordinate := coordinate
+			values = append(values, metricValue{
+				name: coordinate,
+				get: func(bucket *simplejson.Json) null.Float {
+					return castToNullFloat(bucket.GetPath(metric.ID, "location", coordinate))
+				},
+			})
+		}
+		return values
+	}
+
+	return nil
+}
+
+// isDocumentQuery returns true for raw_data and raw_document queries, which return the documents themselves
+func isDocumentQuery(target *Query) bool {
+	if len(target.BucketAggs) > 0 || len(target.Metrics) == 0 {
+		return false
+	}
+	return target.Metrics[0].Type == rawDataType || target.Metrics[0].Type == rawDocumentType
+}
+
 func findAgg(target *Query, aggID string) (*BucketAgg, error) {
 	for _, v := range target.BucketAggs {
 		if aggID == v.ID {
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/opensearch-datasource/pkg/opensearch/client"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -542,52 +543,117 @@ func Test_ResponseParser_test(t *testing.T) {
 		assert.EqualValues(t, 8, *seriesThree.Fields[1].At(1).(*float64))
 	})
 
-	// TODO: this test will require some conversion of tables to data frames, original work in Elasticsearch https://github.com/grafana/grafana/pull/34710; https://github.com/grafana/opensearch-datasource/issues/175
-	//t.Run("Histogram response", func(t *testing.T) {
-	//	targets := map[string]string{
-	//		"A": `{
-	//				"timeField": "@timestamp",
-	//				"metrics": [{ "type": "count", "id": "1" }],
-	//	 "bucketAggs": [{ "type": "histogram", "field": "bytes", "id": "3" }]
-	//			}`,
-	//	}
-	//	response := `{
-	//	   "responses": [
-	//		 {
-	//		   "aggregations": {
-	//			 "3": {
-	//			   "buckets": [{ "doc_count": 1, "key": 1000 }, { "doc_count": 3, "key": 2000 }, { "doc_count": 2, "key": 3000 }]
-	//			 }
-	//		   }
-	//		 }
-	//	   ]
-	//			}`
-	//	rp, err := newResponseParserForTest(targets, response)
-	//	assert.Nil(t, err)
-	//	result, err := rp.getTimeSeries()
-	//	assert.Nil(t, err)
-	//	require.Len(t, result.Responses, 1)
-	//
-	//	queryRes := result.Responses["A"]
-	//	assert.NotNil(t, queryRes)
-	//	assert.Len(t, queryRes.Frames, 1)
-	//So(queryRes.Tables, ShouldHaveLength, 1)
-	//
-	//rows := queryRes.Tables[0].Rows
-	//So(rows, ShouldHaveLength, 3)
-	//cols := queryRes.Tables[0].Columns
-	//So(cols, ShouldHaveLength, 2)
-	//
-	//So(cols[0].Text, ShouldEqual, "bytes")
-	//So(cols[1].Text, ShouldEqual, "Count")
-	//
-	//So(rows[0][0].(null.Float).Float64, ShouldEqual, 1000)
-	//So(rows[0][1].(null.Float).Float64, ShouldEqual, 1)
-	//So(rows[1][0].(null.Float).Float64, ShouldEqual, 2000)
-	//So(rows[1][1].(null.Float).Float64, ShouldEqual, 3)
-	//So(rows[2][0].(null.Float).Float64, ShouldEqual, 3000)
-	//So(rows[2][1].(null.Float).Float64, ShouldEqual, 2)
-	//})
+	t.Run("Logs query", func(t *testing.T) {
+		targets := map[string]string{
+			"A": `{
+					"timeField": "@timestamp",
+					"metrics": [{ "type": "logs", "id": "1" }]
+				}`,
+		}
+		response := `{
+			"responses": [
+				{
+					"hits": {
+						"total": 2,
+						"hits": [
+							{
+								"_id": "1",
+								"_index": "logs-2018.05.15",
+								"_source": {
+									"@timestamp": "2018-05-15T17:50:00.000Z",
+									"message": "hello world",
+									"severity": "info",
+									"host": { "name": "server-1" },
+									"latency": 12
+								},
+								"highlight": { "message": ["@HIGHLIGHT@hello@/HIGHLIGHT@ world"] }
+							},
+							{
+								"_id": "2",
+								"_index": "logs-2018.05.15",
+								"_source": {
+									"message": "goodbye",
+									"severity": "error",
+									"host": { "name": "server-2" }
+								},
+								"fields": { "@timestamp": ["2018-05-15T17:51:00.000Z"] }
+							}
+						]
+					}
+				}
+			]
+		}`
+		rp, err := newResponseParserForTest(targets, response)
+		require.NoError(t, err)
+		rp.ConfiguredFields.LogMessageField = "message"
+		rp.ConfiguredFields.LogLevelField = "severity"
+		result, err := rp.getTimeSeries()
+		require.NoError(t, err)
+
+		queryRes := result.Responses["A"]
+		require.Len(t, queryRes.Frames, 1)
+		frame := queryRes.Frames[0]
+		assert.Equal(t, data.VisTypeLogs, string(frame.Meta.PreferredVisualization))
+		assert.Equal(t, map[string]interface{}{"searchWords": []string{"hello"}}, frame.Meta.Custom)
+		require.Equal(t, 2, frame.Rows())
+
+		fieldNames := make([]string, 0)
+		for _, f := range frame.Fields {
+			fieldNames = append(fieldNames, f.Name)
+		}
+		assert.Equal(t, []string{"@timestamp", "message", "level", "_id", "_index", "_source", "host.name", "latency", "severity"}, fieldNames)
+
+		assert.Equal(t, time.Date(2018, time.May, 15, 17, 50, 0, 0, time.UTC), *frame.Fields[0].At(0).(*time.Time))
+		assert.Equal(t, time.Date(2018, time.May, 15, 17, 51, 0, 0, time.UTC), *frame.Fields[0].At(1).(*time.Time))
+		assert.Equal(t, "hello world", *frame.Fields[1].At(0).(*string))
+		assert.Equal(t, "error", *frame.Fields[2].At(1).(*string))
+		assert.Equal(t, "server-2", *frame.Fields[6].At(1).(*string))
+		assert.EqualValues(t, 12, *frame.Fields[7].At(0).(*float64))
+		assert.Nil(t, frame.Fields[7].At(1))
+	})
+
+	t.Run("Histogram response", func(t *testing.T) {
+		targets := map[string]string{
+			"A": `{
+				"timeField": "@timestamp",
+				"metrics": [{ "type": "count", "id": "1" }],
+		 "bucketAggs": [{ "type": "histogram", "field": "bytes", "id": "3" }]
+				}`,
+		}
+		response := `{
+		   "responses": [
+			 {
+			   "aggregations": {
+				 "3": {
+				   "buckets": [{ "doc_count": 1, "key": 1000 }, { "doc_count": 3, "key": 2000 }, { "doc_count": 2, "key": 3000 }]
+				 }
+			   }
+			 }
+		   ]
+				}`
+		rp, err := newResponseParserForTest(targets, response)
+		assert.Nil(t, err)
+		result, err := rp.getTimeSeries()
+		assert.Nil(t, err)
+		require.Len(t, result.Responses, 1)
+
+		queryRes := result.Responses["A"]
+		assert.NotNil(t, queryRes)
+		require.Len(t, queryRes.Frames, 1)
+
+		frame := queryRes.Frames[0]
+		require.Len(t, frame.Fields, 2)
+		require.Equal(t, 3, frame.Rows())
+		assert.Equal(t, "bytes", frame.Fields[0].Name)
+		assert.Equal(t, "Count", frame.Fields[1].Name)
+
+		assert.EqualValues(t, 1000, *frame.Fields[0].At(0).(*float64))
+		assert.EqualValues(t, 1, *frame.Fields[1].At(0).(*float64))
+		assert.EqualValues(t, 2000, *frame.Fields[0].At(1).(*float64))
+		assert.EqualValues(t, 3, *frame.Fields[1].At(1).(*float64))
+		assert.EqualValues(t, 3000, *frame.Fields[0].At(2).(*float64))
+		assert.EqualValues(t, 2, *frame.Fields[1].At(2).(*float64))
+	})
 
 	t.Run("With two filters agg", func(t *testing.T) {
 		targets := map[string]string{
@@ -728,114 +794,829 @@ func Test_ResponseParser_test(t *testing.T) {
 		assert.EqualValues(t, 200, *seriesTwo.Fields[1].At(0).(*float64))
 	})
 
-	// TODO: similar to above, this test will require some conversion of tables to data frames, original work in Elasticsearch https://github.com/grafana/grafana/pull/34710; https://github.com/grafana/opensearch-datasource/issues/175
-	//t.Run("No group by time", func(t *testing.T) {
-	//	targets := map[string]string{
-	//		"A": `{
-	//				"timeField": "@timestamp",
-	//				"metrics": [{ "type": "avg", "id": "1" }, { "type": "count" }],
-	//	 "bucketAggs": [{ "type": "terms", "field": "host", "id": "2" }]
-	//			}`,
-	//	}
-	//	response := `{
-	//	   "responses": [
-	//		 {
-	//		   "aggregations": {
-	//			 "2": {
-	//			   "buckets": [
-	//				 {
-	//				   "1": { "value": 1000 },
-	//				   "key": "server-1",
-	//				   "doc_count": 369
-	//				 },
-	//				 {
-	//				   "1": { "value": 2000 },
-	//				   "key": "server-2",
-	//				   "doc_count": 200
-	//				 }
-	//			   ]
-	//			 }
-	//		   }
-	//		 }
-	//	   ]
-	//			}`
-	//	rp, err := newResponseParserForTest(targets, response)
-	//	assert.Nil(t, err)
-	//	result, err := rp.getTimeSeries()
-	//	assert.Nil(t, err)
-	//	require.Len(t, result.Responses, 1)
-	//
-	//	queryRes := result.Responses["A"]
-	//	assert.NotNil(t, queryRes)
-	//	So(queryRes.Tables, ShouldHaveLength, 1)
-	//
-	//	rows := queryRes.Tables[0].Rows
-	//	So(rows, ShouldHaveLength, 2)
-	//	cols := queryRes.Tables[0].Columns
-	//	So(cols, ShouldHaveLength, 3)
-	//
-	//	So(cols[0].Text, ShouldEqual, "host")
-	//	So(cols[1].Text, ShouldEqual, "Average")
-	//	So(cols[2].Text, ShouldEqual, "Count")
-	//
-	//	So(rows[0][0].(string), ShouldEqual, "server-1")
-	//	So(rows[0][1].(null.Float).Float64, ShouldEqual, 1000)
-	//	So(rows[0][2].(null.Float).Float64, ShouldEqual, 369)
-	//	So(rows[1][0].(string), ShouldEqual, "server-2")
-	//	So(rows[1][1].(null.Float).Float64, ShouldEqual, 2000)
-	//	So(rows[1][2].(null.Float).Float64, ShouldEqual, 200)
-	//})
-	//
-	// TODO: similar to above, this test will require some conversion of tables to data frames, original work in Elasticsearch https://github.com/grafana/grafana/pull/34710; https://github.com/grafana/opensearch-datasource/issues/175
-	//t.Run("Multiple metrics of same type", func(t *testing.T) {
-	//	targets := map[string]string{
-	//		"A": `{
-	//					"timeField": "@timestamp",
-	//					"metrics": [{ "type": "avg", "field": "test", "id": "1" }, { "type": "avg", "field": "test2", "id": "2" }],
-	//		 "bucketAggs": [{ "type": "terms", "field": "host", "id": "2" }]
-	//				}`,
-	//	}
-	//	response := `{
-	//	   "responses": [
-	//		 {
-	//		   "aggregations": {
-	//			 "2": {
-	//			   "buckets": [
-	//				 {
-	//				   "1": { "value": 1000 },
-	//				   "2": { "value": 3000 },
-	//				   "key": "server-1",
-	//				   "doc_count": 369
-	//				 }
-	//			   ]
-	//			 }
-	//		   }
-	//		 }
-	//	   ]
-	//			}`
-	//	rp, err := newResponseParserForTest(targets, response)
-	//	assert.Nil(t, err)
-	//	result, err := rp.getTimeSeries()
-	//	assert.Nil(t, err)
-	//	require.Len(t, result.Responses, 1)
-	//
-	//	queryRes := result.Responses["A"]
-	//	assert.NotNil(t, queryRes)
-	//	So(queryRes.Tables, ShouldHaveLength, 1)
-	//
-	//	rows := queryRes.Tables[0].Rows
-	//	So(rows, ShouldHaveLength, 1)
-	//	cols := queryRes.Tables[0].Columns
-	//	So(cols, ShouldHaveLength, 3)
-	//
-	//	So(cols[0].Text, ShouldEqual, "host")
-	//	So(cols[1].Text, ShouldEqual, "Average test")
-	//	So(cols[2].Text, ShouldEqual, "Average test2")
-	//
-	//	So(rows[0][0].(string), ShouldEqual, "server-1")
-	//	So(rows[0][1].(null.Float).Float64, ShouldEqual, 1000)
-	//	So(rows[0][2].(null.Float).Float64, ShouldEqual, 3000)
-	//})
+	t.Run("No group by time", func(t *testing.T) {
+		targets := map[string]string{
+			"A": `{
+				"timeField": "@timestamp",
+				"metrics": [{ "type": "avg", "id": "1" }, { "type": "count" }],
+		 "bucketAggs": [{ "type": "terms", "field": "host", "id": "2" }]
+				}`,
+		}
+		response := `{
+		   "responses": [
+			 {
+			   "aggregations": {
+				 "2": {
+				   "buckets": [
+					 {
+					   "1": { "value": 1000 },
+					   "key": "server-1",
+					   "doc_count": 369
+					 },
+					 {
+					   "1": { "value": 2000 },
+					   "key": "server-2",
+					   "doc_count": 200
+					 }
+				   ]
+				 }
+			   }
+			 }
+		   ]
+				}`
+		rp, err := newResponseParserForTest(targets, response)
+		assert.Nil(t, err)
+		result, err := rp.getTimeSeries()
+		assert.Nil(t, err)
+		require.Len(t, result.Responses, 1)
+
+		queryRes := result.Responses["A"]
+		assert.NotNil(t, queryRes)
+		require.Len(t, queryRes.Frames, 1)
+
+		frame := queryRes.Frames[0]
+		require.Len(t, frame.Fields, 3)
+		require.Equal(t, 2, frame.Rows())
+		assert.Equal(t, "host", frame.Fields[0].Name)
+		assert.Equal(t, "Average", frame.Fields[1].Name)
+		assert.Equal(t, "Count", frame.Fields[2].Name)
+
+		assert.Equal(t, "server-1", *frame.Fields[0].At(0).(*string))
+		assert.EqualValues(t, 1000, *frame.Fields[1].At(0).(*float64))
+		assert.EqualValues(t, 369, *frame.Fields[2].At(0).(*float64))
+		assert.Equal(t, "server-2", *frame.Fields[0].At(1).(*string))
+		assert.EqualValues(t, 2000, *frame.Fields[1].At(1).(*float64))
+		assert.EqualValues(t, 200, *frame.Fields[2].At(1).(*float64))
+	})
+
+	t.Run("Multiple metrics of same type", func(t *testing.T) {
+		targets := map[string]string{
+			"A": `{
+					"timeField": "@timestamp",
+					"metrics": [{ "type": "avg", "field": "test", "id": "1" }, { "type": "avg", "field": "test2", "id": "2" }],
+		 "bucketAggs": [{ "type": "terms", "field": "host", "id": "2" }]
+				}`,
+		}
+		response := `{
+		   "responses": [
+			 {
+			   "aggregations": {
+				 "2": {
+				   "buckets": [
+					 {
+					   "1": { "value": 1000 },
+					   "2": { "value": 3000 },
+					   "key": "server-1",
+					   "doc_count": 369
+					 }
+				   ]
+				 }
+			   }
+			 }
+		   ]
+				}`
+		rp, err := newResponseParserForTest(targets, response)
+		assert.Nil(t, err)
+		result, err := rp.getTimeSeries()
+		assert.Nil(t, err)
+		require.Len(t, result.Responses, 1)
+
+		queryRes := result.Responses["A"]
+		assert.NotNil(t, queryRes)
+		require.Len(t, queryRes.Frames, 1)
+
+		frame := queryRes.Frames[0]
+		require.Len(t, frame.Fields, 3)
+		require.Equal(t, 1, frame.Rows())
+		assert.Equal(t, "host", frame.Fields[0].Name)
+		assert.Equal(t, "Average test", frame.Fields[1].Name)
+		assert.Equal(t, "Average test2", frame.Fields[2].Name)
+
+		assert.Equal(t, "server-1", *frame.Fields[0].At(0).(*string))
+		assert.EqualValues(t, 1000, *frame.Fields[1].At(0).(*float64))
+		assert.EqualValues(t, 3000, *frame.Fields[2].At(0).(*float64))
+	})
+
+	t.Run("Extended stats and percentiles without group by time", func(t *testing.T) {
+		targets := map[string]string{
+			"A": `{
+				"timeField": "@timestamp",
+				"metrics": [
+					{ "type": "extended_stats", "field": "@value", "id": "1", "meta": { "max": true, "std_deviation_bounds_upper": true } },
+					{ "type": "percentiles", "field": "@value", "id": "3", "settings": { "percents": ["75", "90"] } }
+				],
+		 "bucketAggs": [{ "type": "terms", "field": "host", "id": "2" }]
+				}`,
+		}
+		response := `{
+		   "responses": [
+			 {
+			   "aggregations": {
+				 "2": {
+				   "buckets": [
+					 {
+					   "1": { "max": 10.2, "std_deviation_bounds": { "upper": 3, "lower": -2 } },
+					   "3": { "values": { "75": 3.3, "90": 5.5 } },
+					   "key": "server-1",
+					   "doc_count": 10
+					 }
+				   ]
+				 }
+			   }
+			 }
+		   ]
+				}`
+		rp, err := newResponseParserForTest(targets, response)
+		assert.Nil(t, err)
+		result, err := rp.getTimeSeries()
+		assert.Nil(t, err)
+		require.Len(t, result.Responses, 1)
+
+		queryRes := result.Responses["A"]
+		assert.NotNil(t, queryRes)
+		require.Len(t, queryRes.Frames, 1)
+
+		frame := queryRes.Frames[0]
+		require.Len(t, frame.Fields, 5)
+		require.Equal(t, 1, frame.Rows())
+		assert.Equal(t, "host", frame.Fields[0].Name)
+		assert.Equal(t, "Max", frame.Fields[1].Name)
+		assert.Equal(t, "Std Dev Upper", frame.Fields[2].Name)
+		assert.Equal(t, "p75 @value", frame.Fields[3].Name)
+		assert.Equal(t, "p90 @value", frame.Fields[4].Name)
+
+		assert.EqualValues(t, 10.2, *frame.Fields[1].At(0).(*float64))
+		assert.EqualValues(t, 3, *frame.Fields[2].At(0).(*float64))
+		assert.EqualValues(t, 3.3, *frame.Fields[3].At(0).(*float64))
+		assert.EqualValues(t, 5.5, *frame.Fields[4].At(0).(*float64))
+	})
+
+	t.Run("With stats, percentile ranks and top metrics", func(t *testing.T) {
+		targets := map[string]string{
+			"A": `{
+				"timeField": "@timestamp",
+				"metrics": [
+					{ "type": "stats", "field": "@value", "id": "1", "meta": { "min": true, "max": true } },
+					{ "type": "percentile_ranks", "field": "@value", "id": "2", "settings": { "values": [5, 10] } },
+					{ "type": "top_metrics", "id": "3", "settings": { "metrics": ["cpu"], "orderBy": "@timestamp" } }
+				],
+				"bucketAggs": [{ "type": "date_histogram", "field": "@timestamp", "id": "4" }]
+			}`,
+		}
+		response := `{
+			"responses": [
+				{
+					"aggregations": {
+						"4": {
+							"buckets": [
+								{
+									"1": { "count": 2, "min": 3, "max": 7, "avg": 5, "sum": 10 },
+									"2": { "values": { "5.0": 40, "10.0": 90 } },
+									"3": { "top": [{ "sort": [1000], "metrics": { "cpu": 0.5 } }] },
+									"doc_count": 2,
+									"key": 1000
+								},
+								{
+									"1": { "count": 1, "min": 4, "max": 4, "avg": 4, "sum": 4 },
+									"2": { "values": { "5.0": 50, "10.0": 100 } },
+									"3": { "top": [{ "sort": [2000], "metrics": { "cpu": 0.7 } }] },
+									"doc_count": 1,
+									"key": 2000
+								}
+							]
+						}
+					}
+				}
+			]
+		}`
+		rp, err := newResponseParserForTest(targets, response)
+		require.NoError(t, err)
+		result, err := rp.getTimeSeries()
+		require.NoError(t, err)
+
+		frames := result.Responses["A"].Frames
+		require.Len(t, frames, 5)
+
+		expected := []struct {
+			name   string
+			values []float64
+		}{
+			{"Min @value", []float64{3, 4}},
+			{"Max @value", []float64{7, 4}},
+			{"rank 10.0 @value", []float64{90, 100}},
+			{"rank 5.0 @value", []float64{40, 50}},
+			{"Top Metrics cpu", []float64{0.5, 0.7}},
+		}
+		for i, e := range expected {
+			assert.Equal(t, e.name, frames[i].Name)
+			require.Equal(t, 2, frames[i].Fields[1].Len())
+			for j, value := range e.values {
+				assert.EqualValues(t, value, *frames[i].Fields[1].At(j).(*float64))
+			}
+		}
+	})
+
+	t.Run("With boxplot and string stats in a table", func(t *testing.T) {
+		targets := map[string]string{
+			"A": `{
+				"timeField": "@timestamp",
+				"metrics": [
+					{ "type": "boxplot", "field": "latency", "id": "1", "meta": { "q2": true } },
+					{ "type": "string_stats", "field": "message", "id": "3", "meta": { "avg_length": true } }
+				],
+				"bucketAggs": [{ "type": "terms", "field": "host", "id": "2" }]
+			}`,
+		}
+		response := `{
+			"responses": [
+				{
+					"aggregations": {
+						"2": {
+							"buckets": [
+								{
+									"1": { "min": 1, "max": 9, "q1": 2, "q2": 4, "q3": 6, "lower": 1, "upper": 9 },
+									"3": { "count": 10, "min_length": 3, "max_length": 12, "avg_length": 7.5, "entropy": 4.2 },
+									"key": "server-1",
+									"doc_count": 10
+								}
+							]
+						}
+					}
+				}
+			]
+		}`
+		rp, err := newResponseParserForTest(targets, response)
+		require.NoError(t, err)
+		result, err := rp.getTimeSeries()
+		require.NoError(t, err)
+
+		frames := result.Responses["A"].Frames
+		require.Len(t, frames, 1)
+
+		frame := frames[0]
+		require.Len(t, frame.Fields, 3)
+		assert.Equal(t, "host", frame.Fields[0].Name)
+		assert.Equal(t, "Median latency", frame.Fields[1].Name)
+		assert.Equal(t, "Avg Length message", frame.Fields[2].Name)
+		assert.EqualValues(t, 4, *frame.Fields[1].At(0).(*float64))
+		assert.EqualValues(t, 7.5, *frame.Fields[2].At(0).(*float64))
+	})
+
+	t.Run("With sibling pipelines and bucket_sort", func(t *testing.T) {
+		targets := map[string]string{
+			"A": `{
+				"timeField": "@timestamp",
+				"metrics": [
+					{ "type": "sum", "field": "@value", "id": "1" },
+					{ "type": "bucket_sort", "id": "2", "settings": { "sort": [{ "field": "1" }] } },
+					{ "type": "avg_bucket", "field": "1", "pipelineAgg": "1", "id": "3" },
+					{ "type": "stats_bucket", "field": "1", "pipelineAgg": "1", "id": "4", "meta": { "max": true } }
+				],
+				"bucketAggs": [{ "type": "date_histogram", "field": "@timestamp", "id": "5" }]
+			}`,
+		}
+		response := `{
+			"responses": [
+				{
+					"aggregations": {
+						"5": {
+							"buckets": [
+								{ "1": { "value": 30 }, "doc_count": 3, "key": 2000 },
+								{ "1": { "value": 20 }, "doc_count": 2, "key": 3000 },
+								{ "1": { "value": 10 }, "doc_count": 1, "key": 1000 }
+							]
+						},
+						"3": { "value": 20 },
+						"4": { "count": 3, "min": 10, "max": 30, "avg": 20, "sum": 60 }
+					}
+				}
+			]
+		}`
+		rp, err := newResponseParserForTest(targets, response)
+		require.NoError(t, err)
+		result, err := rp.getTimeSeries()
+		require.NoError(t, err)
+
+		frames := result.Responses["A"].Frames
+		require.Len(t, frames, 3)
+
+		sum := frames[0]
+		assert.Equal(t, "Sum @value", sum.Name)
+		require.Equal(t, 3, sum.Rows())
+		for i, value := range []float64{10, 30, 20} {
+			assert.Equal(t, time.UnixMilli(int64(1000*(i+1))).UTC(), *sum.Fields[0].At(i).(*time.Time))
+			assert.EqualValues(t, value, *sum.Fields[1].At(i).(*float64))
+		}
+
+		avgBucket := frames[1]
+		assert.Equal(t, "Average Bucket Sum 1", avgBucket.Name)
+		require.Equal(t, 2, avgBucket.Rows())
+		assert.Equal(t, time.UnixMilli(1000).UTC(), *avgBucket.Fields[0].At(0).(*time.Time))
+		assert.Equal(t, time.UnixMilli(3000).UTC(), *avgBucket.Fields[0].At(1).(*time.Time))
+		assert.EqualValues(t, 20, *avgBucket.Fields[1].At(0).(*float64))
+		assert.EqualValues(t, 20, *avgBucket.Fields[1].At(1).(*float64))
+
+		maxBucket := frames[2]
+		assert.Equal(t, "Max Sum @value", maxBucket.Name)
+		assert.EqualValues(t, 30, *maxBucket.Fields[1].At(0).(*float64))
+	})
+
+	t.Run("With filters in a table", func(t *testing.T) {
+		targets := map[string]string{
+			"A": `{
+				"timeField": "@timestamp",
+				"metrics": [{ "type": "count", "id": "1" }],
+				"bucketAggs": [{
+					"type": "filters",
+					"id": "2",
+					"settings": { "filters": [{ "query": "@metric:cpu", "label": "" }, { "query": "@metric:logins.count", "label": "" }] }
+				}]
+			}`,
+		}
+		response := `{
+			"responses": [
+				{
+					"aggregations": {
+						"2": {
+							"buckets": {
+								"@metric:logins.count": { "doc_count": 3 },
+								"@metric:cpu": { "doc_count": 5 }
+							}
+						}
+					}
+				}
+			]
+		}`
+		rp, err := newResponseParserForTest(targets, response)
+		require.NoError(t, err)
+		result, err := rp.getTimeSeries()
+		require.NoError(t, err)
+
+		frames := result.Responses["A"].Frames
+		require.Len(t, frames, 1)
+		frame := frames[0]
+		require.Len(t, frame.Fields, 2)
+		assert.Equal(t, "filter", frame.Fields[0].Name)
+		assert.Equal(t, "Count", frame.Fields[1].Name)
+		require.Equal(t, 2, frame.Rows())
+		assert.Equal(t, "@metric:cpu", *frame.Fields[0].At(0).(*string))
+		assert.EqualValues(t, 5, *frame.Fields[1].At(0).(*float64))
+		assert.Equal(t, "@metric:logins.count", *frame.Fields[0].At(1).(*string))
+		assert.EqualValues(t, 3, *frame.Fields[1].At(1).(*float64))
+	})
+
+	t.Run("With sibling pipelines in a table", func(t *testing.T) {
+		targets := map[string]string{
+			"A": `{
+				"timeField": "@timestamp",
+				"metrics": [
+					{ "type": "sum", "field": "@value", "id": "1" },
+					{ "type": "avg_bucket", "field": "1", "pipelineAgg": "1", "id": "3" },
+					{ "type": "stats_bucket", "field": "1", "pipelineAgg": "1", "id": "4", "meta": { "max": true } }
+				],
+				"bucketAggs": [{ "type": "terms", "field": "host", "id": "2" }]
+			}`,
+		}
+		response := `{
+			"responses": [
+				{
+					"aggregations": {
+						"2": {
+							"buckets": [
+								{ "1": { "value": 30 }, "key": "server-1", "doc_count": 3 },
+								{ "1": { "value": 10 }, "key": "server-2", "doc_count": 1 }
+							]
+						},
+						"3": { "value": 20 },
+						"4": { "count": 2, "min": 10, "max": 30, "avg": 20, "sum": 40 }
+					}
+				}
+			]
+		}`
+		rp, err := newResponseParserForTest(targets, response)
+		require.NoError(t, err)
+		result, err := rp.getTimeSeries()
+		require.NoError(t, err)
+
+		frames := result.Responses["A"].Frames
+		require.Len(t, frames, 1)
+		frame := frames[0]
+		require.Len(t, frame.Fields, 4)
+		assert.Equal(t, "host", frame.Fields[0].Name)
+		assert.Equal(t, "Sum", frame.Fields[1].Name)
+		assert.Equal(t, "Average Bucket Sum @value", frame.Fields[2].Name)
+		assert.Equal(t, "Max Sum @value", frame.Fields[3].Name)
+		for i := 0; i < 2; i++ {
+			assert.EqualValues(t, 20, *frame.Fields[2].At(i).(*float64))
+			assert.EqualValues(t, 30, *frame.Fields[3].At(i).(*float64))
+		}
+	})
+
+	t.Run("With bucket_selector in a table", func(t *testing.T) {
+		targets := map[string]string{
+			"A": `{
+				"timeField": "@timestamp",
+				"metrics": [
+					{ "type": "sum", "field": "@value", "id": "1" },
+					{
+						"type": "bucket_selector",
+						"id": "2",
+						"pipelineVariables": [{ "name": "total", "pipelineAgg": "1" }],
+						"settings": { "script": "params.total > 10" }
+					}
+				],
+				"bucketAggs": [{ "type": "terms", "field": "host", "id": "3" }]
+			}`,
+		}
+		response := `{
+			"responses": [
+				{
+					"aggregations": {
+						"3": {
+							"buckets": [{ "1": { "value": 30 }, "key": "server-1", "doc_count": 3 }]
+						}
+					}
+				}
+			]
+		}`
+		rp, err := newResponseParserForTest(targets, response)
+		require.NoError(t, err)
+		result, err := rp.getTimeSeries()
+		require.NoError(t, err)
+
+		frames := result.Responses["A"].Frames
+		require.Len(t, frames, 1)
+		require.Len(t, frames[0].Fields, 2)
+		assert.Equal(t, "host", frames[0].Fields[0].Name)
+		assert.Equal(t, "Sum", frames[0].Fields[1].Name)
+	})
+
+	t.Run("With keyed range buckets", func(t *testing.T) {
+		targets := map[string]string{
+			"A": `{
+				"timeField": "@timestamp",
+				"metrics": [{ "type": "count", "id": "1" }],
+				"bucketAggs": [{
+					"type": "range",
+					"field": "latency",
+					"id": "2",
+					"settings": { "keyed": true, "ranges": [{ "key": "<100ms", "to": 100 }, { "key": "100-500ms", "from": 100, "to": 500 }, { "key": ">500ms", "from": 500 }] }
+				}]
+			}`,
+		}
+		response := `{
+			"responses": [
+				{
+					"aggregations": {
+						"2": {
+							"buckets": {
+								"<100ms": { "to": 100, "doc_count": 7 },
+								"100-500ms": { "from": 100, "to": 500, "doc_count": 2 },
+								">500ms": { "from": 500, "doc_count": 1 }
+							}
+						}
+					}
+				}
+			]
+		}`
+		rp, err := newResponseParserForTest(targets, response)
+		require.NoError(t, err)
+		result, err := rp.getTimeSeries()
+		require.NoError(t, err)
+
+		frames := result.Responses["A"].Frames
+		require.Len(t, frames, 1)
+		require.Equal(t, 3, frames[0].Rows())
+		assert.Equal(t, "latency", frames[0].Fields[0].Name)
+		for i, key := range []string{"<100ms", "100-500ms", ">500ms"} {
+			assert.Equal(t, key, *frames[0].Fields[0].At(i).(*string))
+		}
+		assert.EqualValues(t, 7, *frames[0].Fields[1].At(0).(*float64))
+		assert.EqualValues(t, 1, *frames[0].Fields[1].At(2).(*float64))
+	})
+
+	t.Run("With keyed range buckets without keys", func(t *testing.T) {
+		targets := map[string]string{
+			"A": `{
+				"timeField": "@timestamp",
+				"metrics": [{ "type": "count", "id": "1" }],
+				"bucketAggs": [{
+					"type": "range",
+					"field": "latency",
+					"id": "2",
+					"settings": { "keyed": true, "ranges": [{ "to": 200 }, { "from": 200, "to": 1000 }, { "key": "slow", "from": 1000, "to": 20000000 }, { "from": 20000000 }] }
+				}]
+			}`,
+			"B": `{
+				"timeField": "@timestamp",
+				"metrics": [{ "type": "count", "id": "1" }],
+				"bucketAggs": [{
+					"type": "date_range",
+					"field": "@timestamp",
+					"id": "2",
+					"settings": { "keyed": true, "ranges": [{ "from": "now-1d" }, { "to": "now-1d" }] }
+				}]
+			}`,
+			"C": `{
+				"timeField": "@timestamp",
+				"metrics": [{ "type": "count", "id": "1" }],
+				"bucketAggs": [{
+					"type": "ip_range",
+					"field": "client_ip",
+					"id": "2",
+					"settings": { "keyed": true, "ranges": [{ "mask": "10.0.0.0/24" }, { "from": "10.0.1.0" }] }
+				}]
+			}`,
+		}
+		responses := map[string]string{
+			"A": `{
+				"*-200.0": { "to": 200, "doc_count": 7 },
+				"200.0-1000.0": { "from": 200, "to": 1000, "doc_count": 2 },
+				"slow": { "from": 1000, "to": 20000000, "doc_count": 1 },
+				"2.0E7-*": { "from": 20000000, "doc_count": 0 }
+			}`,
+			"B": `{
+				"2018-05-14T17:55:00.000Z-*": { "from": 1526320500000, "doc_count": 4 },
+				"*-2018-05-14T17:55:00.000Z": { "to": 1526320500000, "doc_count": 3 }
+			}`,
+			"C": `{
+				"10.0.1.0-*": { "from": "10.0.1.0", "doc_count": 2 },
+				"10.0.0.0/24": { "from": "10.0.0.0", "to": "10.0.1.0", "doc_count": 5 }
+			}`,
+		}
+		expectedKeys := map[string][]string{
+			"A": {"*-200.0", "200.0-1000.0", "slow", "2.0E7-*"},
+			"B": {"*-2018-05-14T17:55:00.000Z", "2018-05-14T17:55:00.000Z-*"},
+			"C": {"10.0.0.0/24", "10.0.1.0-*"},
+		}
+
+		for refID, buckets := range responses {
+			rp, err := newResponseParserForTest(map[string]string{refID: targets[refID]}, `{
+				"responses": [{ "aggregations": { "2": { "buckets": `+buckets+` } } }]
+			}`)
+			require.NoError(t, err)
+			result, err := rp.getTimeSeries()
+			require.NoError(t, err)
+
+			frames := result.Responses[refID].Frames
+			require.Len(t, frames, 1)
+			require.Equal(t, len(expectedKeys[refID]), frames[0].Rows())
+			for i, key := range expectedKeys[refID] {
+				assert.Equal(t, key, *frames[0].Fields[0].At(i).(*string))
+			}
+		}
+	})
+
+	t.Run("With unkeyed range buckets", func(t *testing.T) {
+		targets := map[string]string{
+			"A": `{
+				"timeField": "@timestamp",
+				"metrics": [{ "type": "count", "id": "1" }],
+				"bucketAggs": [{ "type": "range", "field": "latency", "id": "2", "settings": { "ranges": [{ "to": 100 }, { "from": 100 }] } }]
+			}`,
+		}
+		response := `{
+			"responses": [
+				{
+					"aggregations": {
+						"2": {
+							"buckets": [
+								{ "key": "*-100.0", "to": 100, "doc_count": 7 },
+								{ "key": "100.0-*", "from": 100, "doc_count": 3 }
+							]
+						}
+					}
+				}
+			]
+		}`
+		rp, err := newResponseParserForTest(targets, response)
+		require.NoError(t, err)
+		result, err := rp.getTimeSeries()
+		require.NoError(t, err)
+
+		frames := result.Responses["A"].Frames
+		require.Len(t, frames, 1)
+		require.Equal(t, 2, frames[0].Rows())
+		assert.Equal(t, "*-100.0", *frames[0].Fields[0].At(0).(*string))
+		assert.EqualValues(t, 3, *frames[0].Fields[1].At(1).(*float64))
+	})
+
+	t.Run("With multi_terms", func(t *testing.T) {
+		targets := map[string]string{
+			"A": `{
+				"timeField": "@timestamp",
+				"metrics": [{ "type": "count", "id": "1" }],
+				"bucketAggs": [
+					{ "type": "multi_terms", "id": "2", "settings": { "fields": ["region", "host"] } },
+					{ "type": "date_histogram", "field": "@timestamp", "id": "3" }
+				]
+			}`,
+		}
+		response := `{
+			"responses": [
+				{
+					"aggregations": {
+						"2": {
+							"buckets": [
+								{
+									"key": ["eu", "server-1"],
+									"key_as_string": "eu|server-1",
+									"doc_count": 4,
+									"3": { "buckets": [{ "doc_count": 4, "key": 1000 }] }
+								},
+								{
+									"key": ["us", 2],
+									"key_as_string": "us|2",
+									"doc_count": 6,
+									"3": { "buckets": [{ "doc_count": 6, "key": 1000 }] }
+								}
+							]
+						}
+					}
+				}
+			]
+		}`
+		rp, err := newResponseParserForTest(targets, response)
+		require.NoError(t, err)
+		result, err := rp.getTimeSeries()
+		require.NoError(t, err)
+
+		frames := result.Responses["A"].Frames
+		require.Len(t, frames, 2)
+		assert.Equal(t, data.Labels{"region": "eu", "host": "server-1"}, frames[0].Fields[1].Labels)
+		assert.Equal(t, data.Labels{"region": "us", "host": "2"}, frames[1].Fields[1].Labels)
+		assert.EqualValues(t, 6, *frames[1].Fields[1].At(0).(*float64))
+	})
+
+	t.Run("With geohash and geotile grids", func(t *testing.T) {
+		for aggType, key := range map[string]string{"geohash_grid": "u33", "geotile_grid": "1/1/0"} {
+			targets := map[string]string{
+				"A": `{
+					"timeField": "@timestamp",
+					"metrics": [{ "type": "count", "id": "1" }],
+					"bucketAggs": [{ "type": "` + aggType + `", "field": "@location", "id": "2" }]
+				}`,
+			}
+			response := `{
+				"responses": [
+					{
+						"aggregations": {
+							"2": { "buckets": [{ "key": "` + key + `", "doc_count": 3 }] }
+						}
+					}
+				]
+			}`
+			rp, err := newResponseParserForTest(targets, response)
+			require.NoError(t, err)
+			result, err := rp.getTimeSeries()
+			require.NoError(t, err)
+
+			frames := result.Responses["A"].Frames
+			require.Len(t, frames, 1)
+			frame := frames[0]
+			require.Len(t, frame.Fields, 4)
+			assert.Equal(t, "@location", frame.Fields[0].Name)
+			assert.Equal(t, key, *frame.Fields[0].At(0).(*string))
+			assert.Equal(t, "Latitude", frame.Fields[1].Name)
+			assert.Equal(t, "Longitude", frame.Fields[2].Name)
+			assert.Equal(t, "Count", frame.Fields[3].Name)
+
+			if aggType == "geohash_grid" {
+				assert.InDelta(t, 52.734375, *frame.Fields[1].At(0).(*float64), 0.000001)
+				assert.InDelta(t, 13.359375, *frame.Fields[2].At(0).(*float64), 0.000001)
+			} else {
+				assert.InDelta(t, 66.513260, *frame.Fields[1].At(0).(*float64), 0.000001)
+				assert.InDelta(t, 90, *frame.Fields[2].At(0).(*float64), 0.000001)
+			}
+		}
+	})
+
+	t.Run("With a geohex grid, geo_centroid and geo_bounds", func(t *testing.T) {
+		targets := map[string]string{
+			"A": `{
+				"timeField": "@timestamp",
+				"metrics": [
+					{ "type": "geo_centroid", "field": "@location", "id": "1" },
+					{ "type": "geo_bounds", "field": "@location", "id": "3" }
+				],
+				"bucketAggs": [{ "type": "geohex_grid", "field": "@location", "id": "2" }]
+			}`,
+		}
+		response := `{
+			"responses": [
+				{
+					"aggregations": {
+						"2": {
+							"buckets": [
+								{
+									"key": "851f1d4bfffffff",
+									"doc_count": 3,
+									"1": { "location": { "lat": 52.52, "lon": 13.4 }, "count": 3 },
+									"3": {
+										"bounds": {
+											"top_left": { "lat": 52.6, "lon": 13.3 },
+											"bottom_right": { "lat": 52.4, "lon": 13.5 }
+										}
+									}
+								}
+							]
+						}
+					}
+				}
+			]
+		}`
+		rp, err := newResponseParserForTest(targets, response)
+		require.NoError(t, err)
+		result, err := rp.getTimeSeries()
+		require.NoError(t, err)
+
+		frames := result.Responses["A"].Frames
+		require.Len(t, frames, 1)
+		frame := frames[0]
+		require.Len(t, frame.Fields, 7)
+		assert.Equal(t, "@location", frame.Fields[0].Name)
+		assert.Equal(t, "Latitude", frame.Fields[1].Name)
+		assert.EqualValues(t, 52.52, *frame.Fields[1].At(0).(*float64))
+		assert.Equal(t, "Longitude", frame.Fields[2].Name)
+		assert.EqualValues(t, 13.4, *frame.Fields[2].At(0).(*float64))
+		assert.Equal(t, "Top Left Latitude @location", frame.Fields[3].Name)
+		assert.EqualValues(t, 52.6, *frame.Fields[3].At(0).(*float64))
+		assert.Equal(t, "Top Left Longitude @location", frame.Fields[4].Name)
+		assert.Equal(t, "Bottom Right Latitude @location", frame.Fields[5].Name)
+		assert.Equal(t, "Bottom Right Longitude @location", frame.Fields[6].Name)
+		assert.EqualValues(t, 13.5, *frame.Fields[6].At(0).(*float64))
+	})
+
+	t.Run("With significant_terms in a table", func(t *testing.T) {
+		targets := map[string]string{
+			"A": `{
+				"timeField": "@timestamp",
+				"metrics": [{ "type": "count", "id": "1" }],
+				"bucketAggs": [{ "type": "significant_terms", "field": "error", "id": "2" }]
+			}`,
+		}
+		response := `{
+			"responses": [
+				{
+					"aggregations": {
+						"2": {
+							"doc_count": 100,
+							"bg_count": 1000,
+							"buckets": [{ "key": "timeout", "doc_count": 20, "score": 0.85, "bg_count": 25 }]
+						}
+					}
+				}
+			]
+		}`
+		rp, err := newResponseParserForTest(targets, response)
+		require.NoError(t, err)
+		result, err := rp.getTimeSeries()
+		require.NoError(t, err)
+
+		frames := result.Responses["A"].Frames
+		require.Len(t, frames, 1)
+		frame := frames[0]
+		require.Len(t, frame.Fields, 4)
+		assert.Equal(t, "error", frame.Fields[0].Name)
+		assert.Equal(t, "Score", frame.Fields[1].Name)
+		assert.Equal(t, "Background Count", frame.Fields[2].Name)
+		assert.Equal(t, "Count", frame.Fields[3].Name)
+		assert.EqualValues(t, 0.85, *frame.Fields[1].At(0).(*float64))
+		assert.EqualValues(t, 25, *frame.Fields[2].At(0).(*float64))
+		assert.EqualValues(t, 20, *frame.Fields[3].At(0).(*float64))
+	})
+
+	t.Run("With auto date histogram", func(t *testing.T) {
+		targets := map[string]string{
+			"A": `{
+				"timeField": "@timestamp",
+				"metrics": [{ "type": "count", "id": "1" }],
+				"bucketAggs": [{ "type": "auto_date_histogram", "field": "@timestamp", "id": "2" }]
+			}`,
+		}
+		response := `{
+			"responses": [
+				{
+					"aggregations": {
+						"2": {
+							"buckets": [{ "doc_count": 10, "key": 1000 }, { "doc_count": 15, "key": 2000 }],
+							"interval": "1s"
+						}
+					}
+				}
+			]
+		}`
+		rp, err := newResponseParserForTest(targets, response)
+		require.NoError(t, err)
+		result, err := rp.getTimeSeries()
+		require.NoError(t, err)
+
+		frames := result.Responses["A"].Frames
+		require.Len(t, frames, 1)
+		assert.Equal(t, "Count", frames[0].Name)
+		require.Equal(t, 2, frames[0].Rows())
+		assert.EqualValues(t, 15, *frames[0].Fields[1].At(1).(*float64))
+	})
 
 	t.Run("With bucket_script", func(t *testing.T) {
 		targets := map[string]string{
@@ -921,134 +1702,144 @@ func Test_ResponseParser_test(t *testing.T) {
 		assert.EqualValues(t, 12, *seriesThree.Fields[1].At(1).(*float64))
 	})
 
-	// TODO: similar to above, this test will require some conversion of tables to data frames, original work in Elasticsearch https://github.com/grafana/grafana/pull/34710; https://github.com/grafana/opensearch-datasource/issues/175
-	//t.Run("Terms with two bucket_script", func(t *testing.T) {
-	//	targets := map[string]string{
-	//		"A": `{
-	//			"timeField": "@timestamp",
-	//			"metrics": [
-	//				{ "id": "1", "type": "sum", "field": "@value" },
-	//			{ "id": "3", "type": "max", "field": "@value" },
-	//			{
-	//					"id": "4",
-	//					"field": "select field",
-	//					"pipelineVariables": [{ "name": "var1", "pipelineAgg": "1" }, { "name": "var2", "pipelineAgg": "3" }],
-	//					"settings": { "script": "params.var1 * params.var2" },
-	//					"type": "bucket_script"
-	//				},
-	//			{
-	//					"id": "5",
-	//					"field": "select field",
-	//					"pipelineVariables": [{ "name": "var1", "pipelineAgg": "1" }, { "name": "var2", "pipelineAgg": "3" }],
-	//					"settings": { "script": "params.var1 * params.var2 * 2" },
-	//					"type": "bucket_script"
-	//			  }
-	//			],
-	// "bucketAggs": [{ "type": "terms", "field": "@timestamp", "id": "2" }]
-	//		}`,
-	//	}
-	//	response := `{
-	//		"responses": [
-	//			{
-	//				"aggregations": {
-	//				"2": {
-	//					"buckets": [
-	//					{
-	//						"1": { "value": 2 },
-	//						"3": { "value": 3 },
-	//						"4": { "value": 6 },
-	//						"5": { "value": 24 },
-	//						"doc_count": 60,
-	//						"key": 1000
-	//					},
-	//					{
-	//						"1": { "value": 3 },
-	//						"3": { "value": 4 },
-	//						"4": { "value": 12 },
-	//						"5": { "value": 48 },
-	//						"doc_count": 60,
-	//						"key": 2000
-	//					}
-	//					]
-	//				}
-	//				}
-	//			}
-	//		]
-	//	}`
-	//	rp, err := newResponseParserForTest(targets, response)
-	//	assert.Nil(t, err)
-	//	result, err := rp.getTimeSeries()
-	//	assert.Nil(t, err)
-	//	require.Len(t, result.Responses, 1)
-	//	queryRes := result.Responses["A"]
-	//	assert.NotNil(t, queryRes)
-	//So(queryRes.Tables[0].Rows, ShouldHaveLength, 2)
-	//So(queryRes.Tables[0].Columns[1].Text, ShouldEqual, "Sum")
-	//So(queryRes.Tables[0].Columns[2].Text, ShouldEqual, "Max")
-	//So(queryRes.Tables[0].Columns[3].Text, ShouldEqual, "params.var1 * params.var2")
-	//So(queryRes.Tables[0].Columns[4].Text, ShouldEqual, "params.var1 * params.var2 * 2")
-	//So(queryRes.Tables[0].Rows[0][1].(null.Float).Float64, ShouldEqual, 2)
-	//So(queryRes.Tables[0].Rows[0][2].(null.Float).Float64, ShouldEqual, 3)
-	//So(queryRes.Tables[0].Rows[0][3].(null.Float).Float64, ShouldEqual, 6)
-	//So(queryRes.Tables[0].Rows[0][4].(null.Float).Float64, ShouldEqual, 24)
-	//So(queryRes.Tables[0].Rows[1][1].(null.Float).Float64, ShouldEqual, 3)
-	//So(queryRes.Tables[0].Rows[1][2].(null.Float).Float64, ShouldEqual, 4)
-	//So(queryRes.Tables[0].Rows[1][3].(null.Float).Float64, ShouldEqual, 12)
-	//So(queryRes.Tables[0].Rows[1][4].(null.Float).Float64, ShouldEqual, 48)
-	//})
-
-	//t.Run("Raw documents query", func(t *testing.T) {
-	//	targets := map[string]string{
-	//		"A": `{
-	//						"timeField": "@timestamp",
-	//						"metrics": [{ "type": "raw_document", "id": "1" }]
-	//					}`,
-	//	}
-	//	response := `{
-	//			    "responses": [
-	//			      {
-	//			        "hits": {
-	//			          "total": 100,
-	//			          "hits": [
-	//			            {
-	//			              "_id": "1",
-	//			              "_type": "type",
-	//			              "_index": "index",
-	//			              "_source": { "sourceProp": "asd" },
-	//			              "fields": { "fieldProp": "field" }
-	//			            },
-	//			            {
-	//			              "_source": { "sourceProp": "asd2" },
-	//			              "fields": { "fieldProp": "field2" }
-	//			            }
-	//			          ]
-	//			        }
-	//			      }
-	//			    ]
-	//				}`
-	//	rp, err := newResponseParserForTest(targets, response)
-	//	assert.Nil(t, err)
-	//	result, err := rp.getTimeSeries()
-	//	assert.Nil(t, err)
-	//	require.Len(t, result.Responses, 1)
-	//
-	//	queryRes := result.Responses["A"]
-	//	assert.NotNil(t, queryRes)
-	//So(queryRes.Tables, ShouldHaveLength, 1)
-	//
-	//rows := queryRes.Tables[0].Rows
-	//So(rows, ShouldHaveLength, 1)
-	//cols := queryRes.Tables[0].Columns
-	//So(cols, ShouldHaveLength, 3)
-	//
-	//So(cols[0].Text, ShouldEqual, "host")
-	//So(cols[1].Text, ShouldEqual, "Average test")
-	//So(cols[2].Text, ShouldEqual, "Average test2")
-	//
-	//So(rows[0][0].(string), ShouldEqual, "server-1")
-	//So(rows[0][1].(null.Float).Float64, ShouldEqual, 1000)
-	//So(rows[0][2].(null.Float).Float64, ShouldEqual, 3000)
-	//})
+	t.Run("Terms with two bucket_script", func(t *testing.T) {
+		targets := map[string]string{
+			"A": `{
+				"timeField": "@timestamp",
+				"metrics": [
+					{ "id": "1", "type": "sum", "field": "@value" },
+					{ "id": "3", "type": "max", "field": "@value" },
+					{
+						"id": "4",
+						"field": "select field",
+						"pipelineVariables": [{ "name": "var1", "pipelineAgg": "1" }, { "name": "var2", "pipelineAgg": "3" }],
+						"settings": { "script": "params.var1 * params.var2" },
+						"type": "bucket_script"
+					},
+					{
+						"id": "5",
+						"field": "select field",
+						"pipelineVariables": [{ "name": "var1", "pipelineAgg": "1" }, { "name": "var2", "pipelineAgg": "3" }],
+						"settings": { "script": "params.var1 * params.var2 * 2" },
+						"type": "bucket_script"
+					}
+				],
+		 "bucketAggs": [{ "type": "terms", "field": "@timestamp", "id": "2" }]
+			}`,
+		}
+		response := `{
+			"responses": [
+				{
+					"aggregations": {
+					"2": {
+						"buckets": [
+						{
+							"1": { "value": 2 },
+							"3": { "value": 3 },
+							"4": { "value": 6 },
+							"5": { "value": 24 },
+							"doc_count": 60,
+							"key": 1000
+						},
+						{
+							"1": { "value": 3 },
+							"3": { "value": 4 },
+							"4": { "value": 12 },
+							"5": { "value": 48 },
+							"doc_count": 60,
+							"key": 2000
+						}
+						]
+					}
+					}
+				}
+			]
+		}`
+		rp, err := newResponseParserForTest(targets, response)
+		assert.Nil(t, err)
+		result, err := rp.getTimeSeries()
+		assert.Nil(t, err)
+		require.Len(t, result.Responses, 1)
+		queryRes := result.Responses["A"]
+		assert.NotNil(t, queryRes)
+		require.Len(t, queryRes.Frames, 1)
+
+		frame := queryRes.Frames[0]
+		require.Len(t, frame.Fields, 5)
+		require.Equal(t, 2, frame.Rows())
+		assert.Equal(t, "Sum", frame.Fields[1].Name)
+		assert.Equal(t, "Max", frame.Fields[2].Name)
+		assert.Equal(t, "params.var1 * params.var2", frame.Fields[3].Name)
+		assert.Equal(t, "params.var1 * params.var2 * 2", frame.Fields[4].Name)
+		assert.EqualValues(t, 2, *frame.Fields[1].At(0).(*float64))
+		assert.EqualValues(t, 3, *frame.Fields[2].At(0).(*float64))
+		assert.EqualValues(t, 6, *frame.Fields[3].At(0).(*float64))
+		assert.EqualValues(t, 24, *frame.Fields[4].At(0).(*float64))
+		assert.EqualValues(t, 3, *frame.Fields[1].At(1).(*float64))
+		assert.EqualValues(t, 4, *frame.Fields[2].At(1).(*float64))
+		assert.EqualValues(t, 12, *frame.Fields[3].At(1).(*float64))
+		assert.EqualValues(t, 48, *frame.Fields[4].At(1).(*float64))
+	})
+
+	t.Run("Raw data query", func(t *testing.T) {
+		targets := map[string]string{
+			"A": `{
+				"timeField": "@timestamp",
+				"metrics": [{ "type": "raw_data", "id": "1" }]
+			}`,
+		}
+		response := `{
+			"responses": [
+				{
+					"hits": {
+						"total": 100,
+						"hits": [
+							{
+								"_id": "1",
+								"_type": "type",
+								"_index": "index",
+								"_source": { "@timestamp": "2019-06-24T09:51:19.765Z", "host": { "name": "server-1" }, "bytes": 10 },
+								"fields": { "@timestamp": ["2019-06-24T09:51:19.765Z"], "fieldProp": ["field"] }
+							},
+							{
+								"_id": "2",
+								"_type": "type",
+								"_index": "index",
+								"_source": { "@timestamp": "2019-06-24T09:52:19.765Z", "host": { "name": "server-2" } }
+							}
+						]
+					}
+				}
+			]
+		}`
+		rp, err := newResponseParserForTest(targets, response)
+		assert.Nil(t, err)
+		result, err := rp.getTimeSeries()
+		assert.Nil(t, err)
+		require.Len(t, result.Responses, 1)
+
+		queryRes := result.Responses["A"]
+		assert.NotNil(t, queryRes)
+		require.Len(t, queryRes.Frames, 1)
+
+		frame := queryRes.Frames[0]
+		assert.Equal(t, data.VisTypeTable, string(frame.Meta.PreferredVisualization))
+		require.Equal(t, 2, frame.Rows())
+
+		fieldNames := make([]string, 0, len(frame.Fields))
+		for _, f := range frame.Fields {
+			fieldNames = append(fieldNames, f.Name)
+		}
+		assert.Equal(t, []string{"@timestamp", "_id", "_index", "_type", "bytes", "fieldProp", "host.name"}, fieldNames)
+
+		assert.Equal(t, time.Date(2019, 6, 24, 9, 51, 19, 765000000, time.UTC), *frame.Fields[0].At(0).(*time.Time))
+		assert.Equal(t, data.FieldTypeNullableFloat64, frame.Fields[4].Type())
+		assert.EqualValues(t, 10, *frame.Fields[4].At(0).(*float64))
+		assert.Nil(t, frame.Fields[4].At(1))
+		assert.Equal(t, "field", *frame.Fields[5].At(0).(*string))
+		assert.Equal(t, "server-1", *frame.Fields[6].At(0).(*string))
+		assert.Equal(t, "server-2", *frame.Fields[6].At(1).(*string))
+	})
 }
 
 func newResponseParserForTest(tsdbQueries map[string]string, responseBody string) (*responseParser, error) {
@@ -1076,10 +1867,14 @@ func newResponseParserForTest(tsdbQueries map[string]string, responseBody string
 	}
 
 	tsQueryParser := newTimeSeriesQueryParser()
-	queries, err := tsQueryParser.parse(tsdbQuery)
-	if err != nil {
-		return nil, err
+	queries := make([]*Query, 0, len(tsdbQuery.Queries))
+	for _, q := range tsdbQuery.Queries {
+		query, err := tsQueryParser.parseQuery(q)
+		if err != nil {
+			return nil, err
+		}
+		queries = append(queries, query)
 	}
 
-	return newResponseParser(response.Responses, queries, nil), nil
+	return newResponseParser(response.Responses, queries, nil, ConfiguredFields{TimeField: "@timestamp"}), nil
 }
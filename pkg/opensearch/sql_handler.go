@@ -0,0 +1,71 @@
+package opensearch
+
+import (
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	es "github.com/grafana/opensearch-datasource/pkg/opensearch/client"
+)
+
+type sqlHandler struct {
+	client   es.Client
+	req      *backend.QueryDataRequest
+	builders map[string]*es.SQLRequestBuilder
+	queries  map[string]*Query
+}
+
+var newSQLHandler = func(client es.Client, req *backend.QueryDataRequest) *sqlHandler {
+	return &sqlHandler{
+		client:   client,
+		req:      req,
+		builders: make(map[string]*es.SQLRequestBuilder),
+		queries:  make(map[string]*Query),
+	}
+}
+
+func (h *sqlHandler) processQuery(q *Query) error {
+	from := h.req.Queries[0].TimeRange.From.UTC().Format("2006-01-02 15:04:05")
+	to := h.req.Queries[0].TimeRange.To.UTC().Format("2006-01-02 15:04:05")
+
+	builder := h.client.SQL()
+	builder.AddSQLQueryString(h.client.GetTimeField(), to, from, q.RawQuery)
+	builder.FetchSize(pageSize(q.MaxRows))
+	h.builders[q.RefID] = builder
+	h.queries[q.RefID] = q
+	return nil
+}
+
+func (h *sqlHandler) executeQueries() (*backend.QueryDataResponse, error) {
+	return executeConcurrently(h.queries, func(q *Query) backend.DataResponse {
+		return h.executeQuery(q, h.builders[q.RefID])
+	}), nil
+}
+
+// executeQuery sends the SQL request of a query, whose response has the same format as a PPL response
+func (h *sqlHandler) executeQuery(q *Query, builder *es.SQLRequestBuilder) backend.DataResponse {
+	req, err := builder.Build()
+	if err != nil {
+		return queryErrorResponse(err)
+	}
+	res, err := h.client.ExecuteSQLQuery(req)
+	if err != nil {
+		return downstreamErrorResponse(err)
+	}
+	truncated, err := fetchPages(h.client, es.LanguageSQL, res, q.MaxRows)
+	if err != nil {
+		return downstreamErrorResponse(err)
+	}
+
+	rp := newPPLResponseParser(res)
+	var queryRes *backend.DataResponse
+	if q.Format == pplFormatTable {
+		queryRes, err = rp.parseTable()
+	} else {
+		queryRes, err = rp.parseTimeSeries()
+	}
+	if err != nil {
+		return queryErrorResponse(err)
+	}
+	if truncated {
+		addTruncationNotice(queryRes, len(res.Datarows), res.Total)
+	}
+	return *queryRes
+}
@@ -1,7 +1,10 @@
 package opensearch
 
 import (
+	"errors"
+	"fmt"
 	"strconv"
+	"sync"
 
 	"github.com/bitly/go-simplejson"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
@@ -29,32 +32,88 @@ func (e *timeSeriesQuery) execute() (*backend.QueryDataResponse, error) {
 
 	handlers[Lucene] = newLuceneHandler(e.client, e.tsdbQuery, e.intervalCalculator)
 	handlers[PPL] = newPPLHandler(e.client, e.tsdbQuery)
+	handlers[SQL] = newSQLHandler(e.client, e.tsdbQuery)
+	handlers[luceneQueryTypeTraces] = newTracesHandler(e.client, e.tsdbQuery)
+
+	// a query that fails only sets the error of its own response
+	result := backend.NewQueryDataResponse()
+	handlerRefIDs := make(map[string][]string)
 
 	tsQueryParser := newTimeSeriesQueryParser()
-	queries, err := tsQueryParser.parse(e.tsdbQuery)
-	if err != nil {
-		return nil, err
-	}
+	for _, dataQuery := range e.tsdbQuery.Queries {
+		q, err := tsQueryParser.parseQuery(dataQuery)
+		if err != nil {
+			result.Responses[dataQuery.RefID] = queryErrorResponse(err)
+			continue
+		}
 
-	for _, q := range queries {
-		if err := handlers[q.QueryType].processQuery(q); err != nil {
-			return nil, err
+		handlerType := q.QueryType
+		if q.QueryType == Lucene && q.LuceneQueryType == luceneQueryTypeTraces {
+			handlerType = luceneQueryTypeTraces
+		}
+		handler, ok := handlers[handlerType]
+		if !ok {
+			result.Responses[q.RefID] = queryErrorResponse(fmt.Errorf("unsupported query type %q", q.QueryType))
+			continue
 		}
+		if err := handler.processQuery(q); err != nil {
+			result.Responses[q.RefID] = queryErrorResponse(err)
+			continue
+		}
+		handlerRefIDs[handlerType] = append(handlerRefIDs[handlerType], q.RefID)
 	}
 
-	responses := make([]*backend.QueryDataResponse, 0)
+	// the client bounds the number of requests the handlers send at the same time
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for handlerType, handler := range handlers {
+		wg.Add(1)
+		go func(handlerType string, handler queryHandler) {
+			defer wg.Done()
+			response, err := handler.executeQueries()
 
-	for _, handler := range handlers {
-		response, err := handler.executeQueries()
-		if err != nil {
-			return nil, err
-		}
-		if response != nil {
-			responses = append(responses, response)
-		}
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				// the queries of a handler share the request that failed
+				for _, refID := range handlerRefIDs[handlerType] {
+					result.Responses[refID] = downstreamErrorResponse(err)
+				}
+				return
+			}
+			if response != nil {
+				for refID, res := range response.Responses {
+					result.Responses[refID] = res
+				}
+			}
+		}(handlerType, handler)
 	}
+	wg.Wait()
 
-	return mergeResponses(responses...), nil
+	return result, nil
+}
+
+// executeConcurrently executes the queries in their own goroutine, the client bounds the number of
+// requests that are sent at the same time
+func executeConcurrently(queries map[string]*Query, execute func(q *Query) backend.DataResponse) *backend.QueryDataResponse {
+	result := backend.NewQueryDataResponse()
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for refID, q := range queries {
+		wg.Add(1)
+		go func(refID string, q *Query) {
+			defer wg.Done()
+			queryRes := execute(q)
+
+			mu.Lock()
+			defer mu.Unlock()
+			result.Responses[refID] = queryRes
+		}(refID, q)
+	}
+	wg.Wait()
+
+	return result
 }
 
 type timeSeriesQueryParser struct{}
@@ -63,40 +122,57 @@ func newTimeSeriesQueryParser() *timeSeriesQueryParser {
 	return &timeSeriesQueryParser{}
 }
 
-func (p *timeSeriesQueryParser) parse(tsdbQuery *backend.QueryDataRequest) ([]*Query, error) {
-	queries := make([]*Query, 0)
-	for _, q := range tsdbQuery.Queries {
-		model, _ := simplejson.NewJson(q.JSON)
-		timeField, err := model.Get("timeField").String()
-		if err != nil {
-			return nil, err
-		}
-		rawQuery := model.Get("query").MustString()
-		queryType := model.Get("queryType").MustString(Lucene)
-		bucketAggs, err := p.parseBucketAggs(model)
-		if err != nil {
-			return nil, err
-		}
-		metrics, err := p.parseMetrics(model)
-		if err != nil {
-			return nil, err
-		}
-		alias := model.Get("alias").MustString("")
-		interval := strconv.FormatInt(q.Interval.Milliseconds(), 10) + "ms"
-
-		queries = append(queries, &Query{
-			TimeField:  timeField,
-			RawQuery:   rawQuery,
-			QueryType:  queryType,
-			BucketAggs: bucketAggs,
-			Metrics:    metrics,
-			Alias:      alias,
-			Interval:   interval,
-			RefID:      q.RefID,
-		})
+func (p *timeSeriesQueryParser) parseQuery(q backend.DataQuery) (*Query, error) {
+	model, err := simplejson.NewJson(q.JSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the query: %w", err)
+	}
+	timeField, err := model.Get("timeField").String()
+	if err != nil {
+		return nil, errors.New("the query has no time field")
+	}
+	rawQuery := model.Get("query").MustString()
+	queryType := model.Get("queryType").MustString(Lucene)
+	bucketAggs, err := p.parseBucketAggs(model)
+	if err != nil {
+		return nil, err
+	}
+	metrics, err := p.parseMetrics(model)
+	if err != nil {
+		return nil, err
+	}
+	adHocFilters, err := p.parseAdHocFilters(model)
+	if err != nil {
+		return nil, err
+	}
+	alias := model.Get("alias").MustString("")
+	// metrics queries that are typed as logs are handled as logs queries
+	isLogsQuery := model.Get("isLogsQuery").MustBool(false) || (len(metrics) > 0 && metrics[0].Type == logsType)
+	luceneQueryType := model.Get("luceneQueryType").MustString()
+	format := model.Get("format").MustString(pplFormatTimeSeries)
+	maxRows := model.Get("maxRows").MustInt(defaultMaxRows)
+	if maxRows <= 0 {
+		return nil, fmt.Errorf("invalid row limit %d, the row limit should be greater than 0", maxRows)
 	}
+	timeZone := model.Get("timeZone").MustString("")
+	interval := strconv.FormatInt(q.Interval.Milliseconds(), 10) + "ms"
 
-	return queries, nil
+	return &Query{
+		TimeField:       timeField,
+		RawQuery:        rawQuery,
+		QueryType:       queryType,
+		BucketAggs:      bucketAggs,
+		Metrics:         metrics,
+		AdHocFilters:    adHocFilters,
+		Alias:           alias,
+		IsLogsQuery:     isLogsQuery,
+		LuceneQueryType: luceneQueryType,
+		Format:          format,
+		MaxRows:         maxRows,
+		TimeZone:        timeZone,
+		Interval:        interval,
+		RefID:           q.RefID,
+	}, nil
 }
 
 func (p *timeSeriesQueryParser) parseBucketAggs(model *simplejson.Json) ([]*BucketAgg, error) {
@@ -124,6 +200,31 @@ func (p *timeSeriesQueryParser) parseBucketAggs(model *simplejson.Json) ([]*Buck
 	return result, nil
 }
 
+// parseAdHocFilters reads the ad hoc filters of the dashboard the frontend adds to the query
+func (p *timeSeriesQueryParser) parseAdHocFilters(model *simplejson.Json) ([]es.AdHocFilter, error) {
+	var result []es.AdHocFilter
+	for _, t := range model.Get("adhocFilters").MustArray() {
+		filterJSON := utils.NewJsonFromAny(t)
+		filter := es.AdHocFilter{
+			Key:      filterJSON.Get("key").MustString(),
+			Operator: filterJSON.Get("operator").MustString(),
+		}
+		if filter.Key == "" {
+			return nil, errors.New("ad hoc filter has no key")
+		}
+		if !es.IsAdHocFilterOperator(filter.Operator) {
+			return nil, fmt.Errorf("unsupported ad hoc filter operator %q", filter.Operator)
+		}
+		// values of number fields can be sent as numbers
+		if value := filterJSON.Get("value").Interface(); value != nil {
+			filter.Value = fmt.Sprint(value)
+		}
+
+		result = append(result, filter)
+	}
+	return result, nil
+}
+
 func (p *timeSeriesQueryParser) parseMetrics(model *simplejson.Json) ([]*MetricAgg, error) {
 	var err error
 	var result []*MetricAgg
@@ -155,15 +256,3 @@ func (p *timeSeriesQueryParser) parseMetrics(model *simplejson.Json) ([]*MetricA
 	}
 	return result, nil
 }
-
-func mergeResponses(responses ...*backend.QueryDataResponse) *backend.QueryDataResponse {
-	result := &backend.QueryDataResponse{
-		Responses: backend.Responses{},
-	}
-	for _, response := range responses {
-		for k, v := range response.Responses {
-			result.Responses[k] = v
-		}
-	}
-	return result
-}
@@ -1,12 +1,15 @@
 package opensearch
 
 import (
+	"errors"
 	"fmt"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/Masterminds/semver"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	es "github.com/grafana/opensearch-datasource/pkg/opensearch/client"
 	"github.com/grafana/opensearch-datasource/pkg/tsdb"
 	. "github.com/smartystreets/goconvey/convey"
@@ -271,6 +274,98 @@ func TestExecuteTimeSeriesQuery(t *testing.T) {
 			So(sr.Size, ShouldEqual, 1337)
 		})
 
+		Convey("With raw data metric", func() {
+			c := newFakeClient(es.OpenSearch, "1.0.0")
+			c.timeField = "timestamp"
+			_, err := executeTsdbQuery(c, `{
+				"timeField": "timestamp",
+				"bucketAggs": [],
+				"metrics": [{ "id": "1", "type": "raw_data", "settings": { "size": "100", "order": "asc" } }]
+			}`, from, to, 15*time.Second)
+			So(err, ShouldBeNil)
+			sr := c.multisearchRequests[0].Requests[0]
+
+			So(sr.Size, ShouldEqual, 100)
+			So(sr.Aggs, ShouldHaveLength, 0)
+			So(sr.Sort["timestamp"], ShouldResemble, map[string]string{"order": "asc", "unmapped_type": "boolean"})
+			So(sr.CustomProps["docvalue_fields"], ShouldResemble, []string{"timestamp"})
+		})
+
+		Convey("With logs query", func() {
+			c := newFakeClient(es.OpenSearch, "1.0.0")
+			_, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"bucketAggs": [{ "type": "date_histogram", "field": "@timestamp", "id": "2" }],
+				"metrics": [{ "id": "1", "type": "logs", "settings": { "size": "100" } }],
+				"query": "level:error"
+			}`, from, to, 15*time.Second)
+			So(err, ShouldBeNil)
+			sr := c.multisearchRequests[0].Requests[0]
+
+			So(sr.Size, ShouldEqual, 100)
+			So(sr.Aggs, ShouldHaveLength, 0)
+			So(sr.Sort["@timestamp"], ShouldResemble, map[string]string{"order": "desc", "unmapped_type": "boolean"})
+			So(sr.CustomProps["docvalue_fields"], ShouldResemble, []string{"@timestamp"})
+			highlight := sr.CustomProps["highlight"].(map[string]interface{})
+			So(highlight["pre_tags"], ShouldResemble, []string{es.HighlightPreTag})
+			So(highlight["post_tags"], ShouldResemble, []string{es.HighlightPostTag})
+			So(sr.Query.Bool.Filters[1].(*es.QueryStringFilter).Query, ShouldEqual, "level:error")
+		})
+
+		Convey("With logs query and default size", func() {
+			c := newFakeClient(es.OpenSearch, "1.0.0")
+			_, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"isLogsQuery": true,
+				"metrics": [{ "id": "1", "type": "count" }]
+			}`, from, to, 15*time.Second)
+			So(err, ShouldBeNil)
+			sr := c.multisearchRequests[0].Requests[0]
+
+			So(sr.Size, ShouldEqual, 500)
+			So(sr.CustomProps["highlight"], ShouldNotBeNil)
+		})
+
+		Convey("With trace list query", func() {
+			c := newFakeClient(es.OpenSearch, "1.0.0")
+			_, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"luceneQueryType": "Traces",
+				"query": "serviceName:frontend"
+			}`, from, to, 15*time.Second)
+			So(err, ShouldBeNil)
+			sr := c.multisearchRequests[0].Requests[0]
+
+			So(sr.Size, ShouldEqual, 0)
+			rangeFilter := sr.Query.Bool.Filters[0].(*es.RangeFilter)
+			So(rangeFilter.Key, ShouldEqual, "startTime")
+			So(rangeFilter.Gte, ShouldEqual, fromStr)
+			So(sr.Query.Bool.Filters[1].(*es.QueryStringFilter).Query, ShouldEqual, "serviceName:frontend")
+
+			tracesAgg := sr.Aggs[0]
+			So(tracesAgg.Key, ShouldEqual, "traces")
+			So(tracesAgg.Aggregation.Aggregation.(*es.TermsAggregation).Field, ShouldEqual, "traceId")
+			So(tracesAgg.Aggregation.Aggs, ShouldHaveLength, 4)
+			So(tracesAgg.Aggregation.Aggs[2].Aggregation.Type, ShouldEqual, "filter")
+		})
+
+		Convey("With trace spans query", func() {
+			c := newFakeClient(es.OpenSearch, "1.0.0")
+			_, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"luceneQueryType": "Traces",
+				"query": "traceId: abc123"
+			}`, from, to, 15*time.Second)
+			So(err, ShouldBeNil)
+			sr := c.multisearchRequests[0].Requests[0]
+
+			So(sr.Size, ShouldEqual, 1000)
+			So(sr.Aggs, ShouldHaveLength, 0)
+			termFilter := sr.Query.Bool.Filters[1].(*es.TermFilter)
+			So(termFilter.Key, ShouldEqual, "traceId")
+			So(termFilter.Value, ShouldEqual, "abc123")
+		})
+
 		Convey("With date histogram agg", func() {
 			c := newFakeClient(es.OpenSearch, "1.0.0")
 			_, err := executeTsdbQuery(c, `{
@@ -293,10 +388,143 @@ func TestExecuteTimeSeriesQuery(t *testing.T) {
 			So(firstLevel.Aggregation.Type, ShouldEqual, "date_histogram")
 			hAgg := firstLevel.Aggregation.Aggregation.(*es.DateHistogramAgg)
 			So(hAgg.Field, ShouldEqual, "@timestamp")
-			So(hAgg.Interval, ShouldEqual, "$__interval")
+			So(hAgg.Interval, ShouldBeEmpty)
+			So(hAgg.FixedInterval, ShouldEqual, "15000ms")
 			So(hAgg.MinDocCount, ShouldEqual, 2)
 		})
 
+		Convey("With date histogram intervals", func() {
+			dateHistogramQuery := func(interval string) string {
+				return fmt.Sprintf(`{
+					"timeField": "@timestamp",
+					"bucketAggs": [{ "id": "2", "type": "date_histogram", "field": "@timestamp", "settings": { "interval": %q } }],
+					"metrics": [{"type": "count", "id": "1" }]
+				}`, interval)
+			}
+			dateHistogramAgg := func(c *fakeClient, i int) *es.DateHistogramAgg {
+				return c.multisearchRequests[0].Requests[i].Aggs[0].Aggregation.Aggregation.(*es.DateHistogramAgg)
+			}
+
+			Convey("Should use calendar intervals for calendar units on Elasticsearch 7.2+", func() {
+				c := newFakeClient(es.Elasticsearch, "7.2.0")
+				_, err := executeTsdbQueries(c, from, to,
+					dateHistogramQuery("1M"),
+					dateHistogramQuery("1q"),
+					dateHistogramQuery("1w"),
+					dateHistogramQuery("1d"),
+				)
+				So(err, ShouldBeNil)
+
+				for i, interval := range []string{"1M", "1q", "1w", "1d"} {
+					agg := dateHistogramAgg(c, i)
+					So(agg.CalendarInterval, ShouldEqual, interval)
+					So(agg.FixedInterval, ShouldBeEmpty)
+					So(agg.Interval, ShouldBeEmpty)
+				}
+			})
+
+			Convey("Should use fixed intervals for the other units on OpenSearch", func() {
+				c := newFakeClient(es.OpenSearch, "1.0.0")
+				_, err := executeTsdbQueries(c, from, to,
+					dateHistogramQuery("30s"),
+					dateHistogramQuery("5m"),
+					dateHistogramQuery("2d"),
+					dateHistogramQuery("2w"),
+				)
+				So(err, ShouldBeNil)
+
+				for i, interval := range []string{"30s", "5m", "2d", "14d"} {
+					agg := dateHistogramAgg(c, i)
+					So(agg.FixedInterval, ShouldEqual, interval)
+					So(agg.CalendarInterval, ShouldBeEmpty)
+				}
+			})
+
+			Convey("Should use the deprecated interval on Elasticsearch before 7.2", func() {
+				c := newFakeClient(es.Elasticsearch, "7.1.0")
+				_, err := executeTsdbQueries(c, from, to, dateHistogramQuery("auto"), dateHistogramQuery("1M"))
+				So(err, ShouldBeNil)
+
+				So(dateHistogramAgg(c, 0).Interval, ShouldEqual, "$__interval")
+				So(dateHistogramAgg(c, 1).Interval, ShouldEqual, "1M")
+				So(dateHistogramAgg(c, 1).CalendarInterval, ShouldBeEmpty)
+			})
+
+			Convey("Should use the deprecated interval for intervals that aren't a duration", func() {
+				c := newFakeClient(es.OpenSearch, "2.5.0")
+				_, err := executeTsdbQuery(c, dateHistogramQuery("$custom_interval"), from, to, 15*time.Second)
+				So(err, ShouldBeNil)
+
+				So(dateHistogramAgg(c, 0).Interval, ShouldEqual, "$custom_interval")
+			})
+
+			Convey("Should return an error for multiple months", func() {
+				c := newFakeClient(es.OpenSearch, "2.5.0")
+				res, err := executeTsdbQuery(c, dateHistogramQuery("3M"), from, to, 15*time.Second)
+				So(err, ShouldBeNil)
+
+				So(res.Responses[""].Error.Error(), ShouldEqual, `invalid date histogram interval "3M", intervals of months, quarters and years should be a single unit, e.g. 1M`)
+				So(c.multisearchRequests, ShouldBeEmpty)
+			})
+		})
+
+		Convey("With date histogram time zones", func() {
+			dateHistogramQuery := func(queryTimeZone, settings string) string {
+				return fmt.Sprintf(`{
+					"timeField": "@timestamp",
+					"timeZone": %q,
+					"bucketAggs": [{ "id": "2", "type": "date_histogram", "field": "@timestamp", "settings": %s }],
+					"metrics": [{"type": "count", "id": "1" }]
+				}`, queryTimeZone, settings)
+			}
+			dateHistogramAgg := func(c *fakeClient, i int) *es.DateHistogramAgg {
+				return c.multisearchRequests[0].Requests[i].Aggs[0].Aggregation.Aggregation.(*es.DateHistogramAgg)
+			}
+
+			Convey("Should use the time zone of the query", func() {
+				c := newFakeClient(es.OpenSearch, "2.5.0")
+				_, err := executeTsdbQueries(c, from, to,
+					dateHistogramQuery("Europe/Berlin", `{ "interval": "1d" }`),
+					dateHistogramQuery("+02:00", `{ "interval": "1d" }`),
+				)
+				So(err, ShouldBeNil)
+
+				So(dateHistogramAgg(c, 0).TimeZone, ShouldEqual, "Europe/Berlin")
+				So(dateHistogramAgg(c, 1).TimeZone, ShouldEqual, "+02:00")
+			})
+
+			Convey("Should prefer the time zone of the aggregation", func() {
+				c := newFakeClient(es.OpenSearch, "2.5.0")
+				_, err := executeTsdbQuery(c, dateHistogramQuery("Europe/Berlin", `{ "interval": "1d", "timeZone": "America/New_York" }`), from, to, 15*time.Second)
+				So(err, ShouldBeNil)
+
+				So(dateHistogramAgg(c, 0).TimeZone, ShouldEqual, "America/New_York")
+			})
+
+			Convey("Should leave UTC and the browser time zone to the cluster", func() {
+				c := newFakeClient(es.OpenSearch, "2.5.0")
+				_, err := executeTsdbQueries(c, from, to,
+					dateHistogramQuery("utc", `{ "interval": "1d" }`),
+					dateHistogramQuery("browser", `{ "interval": "1d" }`),
+					dateHistogramQuery("", `{ "interval": "1d" }`),
+				)
+				So(err, ShouldBeNil)
+
+				for i := 0; i < 3; i++ {
+					So(dateHistogramAgg(c, i).TimeZone, ShouldBeEmpty)
+				}
+			})
+
+			Convey("Should return an error for an unknown time zone", func() {
+				c := newFakeClient(es.OpenSearch, "2.5.0")
+				res, err := executeTsdbQuery(c, dateHistogramQuery("Europe/Nowhere", `{ "interval": "1d" }`), from, to, 15*time.Second)
+				So(err, ShouldBeNil)
+
+				So(res.Responses[""].Error.Error(), ShouldEqual, `invalid time zone "Europe/Nowhere"`)
+				So(c.multisearchRequests, ShouldBeEmpty)
+			})
+		})
+
 		Convey("With histogram agg", func() {
 			c := newFakeClient(es.OpenSearch, "1.0.0")
 			_, err := executeTsdbQuery(c, `{
@@ -349,6 +577,92 @@ func TestExecuteTimeSeriesQuery(t *testing.T) {
 			So(ghGridAgg.Precision, ShouldEqual, 3)
 		})
 
+		Convey("With geotile and geohex grid aggs and geo metrics", func() {
+			c := newFakeClient(es.OpenSearch, "2.5.0")
+			_, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"bucketAggs": [
+					{ "type": "geotile_grid", "field": "@location", "id": "3", "settings": { "precision": 9 } },
+					{ "type": "geohex_grid", "field": "@location", "id": "4" }
+				],
+				"metrics": [
+					{ "type": "geo_bounds", "field": "@location", "id": "1", "settings": { "wrap_longitude": true } },
+					{ "type": "geo_centroid", "field": "@location", "id": "2" }
+				]
+			}`, from, to, 15*time.Second)
+			So(err, ShouldBeNil)
+			sr := c.multisearchRequests[0].Requests[0]
+
+			firstLevel := sr.Aggs[0]
+			So(firstLevel.Aggregation.Type, ShouldEqual, "geotile_grid")
+			geoTileGridAgg := firstLevel.Aggregation.Aggregation.(*es.GeoTileGridAggregation)
+			So(geoTileGridAgg.Field, ShouldEqual, "@location")
+			So(geoTileGridAgg.Precision, ShouldEqual, 9)
+
+			secondLevel := firstLevel.Aggregation.Aggs[0]
+			So(secondLevel.Aggregation.Type, ShouldEqual, "geohex_grid")
+			So(secondLevel.Aggregation.Aggregation.(*es.GeoHexGridAggregation).Precision, ShouldEqual, 5)
+
+			metrics := secondLevel.Aggregation.Aggs
+			So(metrics, ShouldHaveLength, 2)
+			So(metrics[0].Aggregation.Type, ShouldEqual, "geo_bounds")
+			geoBounds := metrics[0].Aggregation.Aggregation.(*es.MetricAggregation)
+			So(geoBounds.Field, ShouldEqual, "@location")
+			So(geoBounds.Settings["wrap_longitude"], ShouldEqual, true)
+			So(metrics[1].Aggregation.Type, ShouldEqual, "geo_centroid")
+		})
+
+		Convey("With weighted average and top metrics", func() {
+			c := newFakeClient(es.OpenSearch, "2.5.0")
+			_, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"bucketAggs": [
+					{ "type": "date_histogram", "field": "@timestamp", "id": "4" }
+				],
+				"metrics": [
+					{ "id": "1", "type": "weighted_avg", "field": "load", "settings": { "weight": "requests" } },
+					{ "id": "2", "type": "top_metrics", "settings": { "metrics": ["cpu", "memory"], "orderBy": "@timestamp" } },
+					{ "id": "3", "type": "boxplot", "field": "latency", "settings": { "compression": 200 } }
+				]
+			}`, from, to, 15*time.Second)
+			So(err, ShouldBeNil)
+			aggs := c.multisearchRequests[0].Requests[0].Aggs[0].Aggregation.Aggs
+			So(aggs, ShouldHaveLength, 3)
+
+			weightedAvg := aggs[0].Aggregation.Aggregation.(*es.MetricAggregation)
+			So(aggs[0].Aggregation.Type, ShouldEqual, "weighted_avg")
+			So(weightedAvg.Field, ShouldBeEmpty)
+			So(weightedAvg.Settings["value"], ShouldResemble, map[string]interface{}{"field": "load"})
+			So(weightedAvg.Settings["weight"], ShouldResemble, map[string]interface{}{"field": "requests"})
+
+			topMetrics := aggs[1].Aggregation.Aggregation.(*es.MetricAggregation)
+			So(aggs[1].Aggregation.Type, ShouldEqual, "top_metrics")
+			So(topMetrics.Settings["metrics"], ShouldResemble, []interface{}{
+				map[string]interface{}{"field": "cpu"},
+				map[string]interface{}{"field": "memory"},
+			})
+			So(topMetrics.Settings["sort"], ShouldResemble, map[string]interface{}{"@timestamp": "desc"})
+			So(topMetrics.Settings["size"], ShouldEqual, 1)
+
+			boxplot := aggs[2].Aggregation.Aggregation.(*es.MetricAggregation)
+			So(aggs[2].Aggregation.Type, ShouldEqual, "boxplot")
+			So(boxplot.Field, ShouldEqual, "latency")
+			So(boxplot.Settings, ShouldContainKey, "compression")
+		})
+
+		Convey("With a top metrics metric without an order by field", func() {
+			c := newFakeClient(es.OpenSearch, "2.5.0")
+			res, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"bucketAggs": [{ "type": "date_histogram", "field": "@timestamp", "id": "4" }],
+				"metrics": [{ "id": "2", "type": "top_metrics", "settings": { "metrics": ["cpu"] } }]
+			}`, from, to, 15*time.Second)
+			So(err, ShouldBeNil)
+
+			So(res.Responses[""].Error.Error(), ShouldEqual, "the top metrics metric 2 needs the metrics to return and a field to order by")
+			So(c.multisearchRequests, ShouldBeEmpty)
+		})
+
 		Convey("With moving average", func() {
 			c := newFakeClient(es.OpenSearch, "1.0.0")
 			_, err := executeTsdbQuery(c, `{
@@ -690,6 +1004,151 @@ func TestExecuteTimeSeriesQuery(t *testing.T) {
 			})
 		})
 
+		Convey("With bucket_selector and bucket_sort", func() {
+			c := newFakeClient(es.OpenSearch, "2.5.0")
+			_, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"bucketAggs": [
+					{ "type": "terms", "field": "host", "id": "4" }
+				],
+				"metrics": [
+					{ "id": "1", "type": "count" },
+					{ "id": "3", "type": "sum", "field": "@value" },
+					{
+						"id": "2",
+						"type": "bucket_selector",
+						"pipelineVariables": [{ "name": "total", "pipelineAgg": "3" }],
+						"settings": { "script": "params.total > 100" }
+					},
+					{
+						"id": "5",
+						"type": "bucket_sort",
+						"settings": { "sort": [{ "field": "1", "order": "asc" }, { "field": "_key" }], "size": 10 }
+					}
+				]
+			}`, from, to, 15*time.Second)
+			So(err, ShouldBeNil)
+			aggs := c.multisearchRequests[0].Requests[0].Aggs[0].Aggregation.Aggs
+			So(aggs, ShouldHaveLength, 3)
+
+			So(aggs[1].Key, ShouldEqual, "2")
+			So(aggs[1].Aggregation.Type, ShouldEqual, "bucket_selector")
+			selector := aggs[1].Aggregation.Aggregation.(*es.PipelineAggregation)
+			So(selector.BucketPath, ShouldResemble, map[string]interface{}{"total": "3"})
+			So(selector.Settings["script"], ShouldEqual, "params.total > 100")
+
+			So(aggs[2].Key, ShouldEqual, "5")
+			So(aggs[2].Aggregation.Type, ShouldEqual, "bucket_sort")
+			bucketSort := aggs[2].Aggregation.Aggregation.(*es.PipelineAggregation)
+			So(bucketSort.BucketPath, ShouldBeNil)
+			So(bucketSort.Settings, ShouldResemble, map[string]interface{}{
+				"sort": []interface{}{
+					map[string]interface{}{"_count": map[string]interface{}{"order": "asc"}},
+					map[string]interface{}{"_key": map[string]interface{}{"order": "desc"}},
+				},
+				"size": 10,
+			})
+		})
+
+		Convey("With sibling pipeline aggregations", func() {
+			c := newFakeClient(es.OpenSearch, "2.5.0")
+			_, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"bucketAggs": [
+					{ "type": "terms", "field": "host", "id": "2" },
+					{ "type": "date_histogram", "field": "@timestamp", "id": "4" }
+				],
+				"metrics": [
+					{ "id": "1", "type": "count" },
+					{ "id": "3", "type": "sum", "field": "@value" },
+					{ "id": "5", "type": "avg_bucket", "field": "3", "pipelineAgg": "3" },
+					{ "id": "6", "type": "max_bucket", "field": "1", "pipelineAgg": "1" },
+					{ "id": "7", "type": "serial_diff", "field": "3", "pipelineAgg": "3", "settings": { "lag": 7 } }
+				]
+			}`, from, to, 15*time.Second)
+			So(err, ShouldBeNil)
+			termsAgg := c.multisearchRequests[0].Requests[0].Aggs[0]
+			So(termsAgg.Key, ShouldEqual, "2")
+			So(termsAgg.Aggregation.Aggs, ShouldHaveLength, 3)
+
+			histogramAgg := termsAgg.Aggregation.Aggs[0]
+			So(histogramAgg.Key, ShouldEqual, "4")
+			So(histogramAgg.Aggregation.Aggs, ShouldHaveLength, 2)
+			serialDiff := histogramAgg.Aggregation.Aggs[1]
+			So(serialDiff.Aggregation.Type, ShouldEqual, "serial_diff")
+			So(serialDiff.Aggregation.Aggregation.(*es.PipelineAggregation).BucketPath, ShouldEqual, "3")
+
+			avgBucket := termsAgg.Aggregation.Aggs[1]
+			So(avgBucket.Key, ShouldEqual, "5")
+			So(avgBucket.Aggregation.Type, ShouldEqual, "avg_bucket")
+			So(avgBucket.Aggregation.Aggregation.(*es.PipelineAggregation).BucketPath, ShouldEqual, "4>3")
+
+			maxBucket := termsAgg.Aggregation.Aggs[2]
+			So(maxBucket.Key, ShouldEqual, "6")
+			So(maxBucket.Aggregation.Aggregation.(*es.PipelineAggregation).BucketPath, ShouldEqual, "4>_count")
+		})
+
+		Convey("With range aggs", func() {
+			c := newFakeClient(es.OpenSearch, "2.5.0")
+			_, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"timeZone": "Europe/Berlin",
+				"bucketAggs": [
+					{
+						"id": "2",
+						"type": "range",
+						"field": "latency",
+						"settings": { "ranges": [{ "key": "<100ms", "to": 100 }, { "from": 100, "to": 500 }, { "from": "500", "to": "" }] }
+					},
+					{
+						"id": "3",
+						"type": "date_range",
+						"field": "@timestamp",
+						"settings": { "ranges": [{ "from": "now-1d/d" }], "format": "yyyy-MM-dd" }
+					},
+					{ "id": "4", "type": "auto_date_histogram", "field": "@timestamp", "settings": { "buckets": 20 } }
+				],
+				"metrics": [{ "type": "count", "id": "1" }]
+			}`, from, to, 15*time.Second)
+			So(err, ShouldBeNil)
+			rangeAgg := c.multisearchRequests[0].Requests[0].Aggs[0]
+			So(rangeAgg.Aggregation.Type, ShouldEqual, "range")
+			ranges := rangeAgg.Aggregation.Aggregation.(*es.RangeAggregation).Ranges
+			So(ranges, ShouldHaveLength, 3)
+			So(ranges[0].Key, ShouldEqual, "<100ms")
+			So(ranges[0].From, ShouldBeNil)
+			So(fmt.Sprint(ranges[0].To), ShouldEqual, "100")
+			So(ranges[2].From, ShouldEqual, "500")
+			So(ranges[2].To, ShouldBeNil)
+
+			dateRangeAgg := rangeAgg.Aggregation.Aggs[0]
+			So(dateRangeAgg.Aggregation.Type, ShouldEqual, "date_range")
+			dateRange := dateRangeAgg.Aggregation.Aggregation.(*es.RangeAggregation)
+			So(dateRange.Ranges[0].From, ShouldEqual, "now-1d/d")
+			So(dateRange.Format, ShouldEqual, "yyyy-MM-dd")
+			So(dateRange.TimeZone, ShouldEqual, "Europe/Berlin")
+
+			autoDateHistogramAgg := dateRangeAgg.Aggregation.Aggs[0]
+			So(autoDateHistogramAgg.Aggregation.Type, ShouldEqual, "auto_date_histogram")
+			autoDateHistogram := autoDateHistogramAgg.Aggregation.Aggregation.(*es.AutoDateHistogramAgg)
+			So(autoDateHistogram.Buckets, ShouldEqual, 20)
+			So(autoDateHistogram.Format, ShouldEqual, es.DateFormatEpochMS)
+			So(autoDateHistogram.TimeZone, ShouldEqual, "Europe/Berlin")
+		})
+
+		Convey("With a range agg without ranges", func() {
+			c := newFakeClient(es.OpenSearch, "2.5.0")
+			res, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"bucketAggs": [{ "id": "2", "type": "ip_range", "field": "client_ip", "settings": {} }],
+				"metrics": [{ "type": "count", "id": "1" }]
+			}`, from, to, 15*time.Second)
+			So(err, ShouldBeNil)
+
+			So(res.Responses[""].Error.Error(), ShouldEqual, "the ip_range aggregation 2 needs at least one range")
+			So(c.multisearchRequests, ShouldBeEmpty)
+		})
+
 		Convey("With Lucene query, should send single multisearch request", func() {
 			c := newFakeClient(es.OpenSearch, "1.0.0")
 			_, err := executeTsdbQuery(c, `{
@@ -711,12 +1170,25 @@ func TestExecuteTimeSeriesQuery(t *testing.T) {
 				"query": "source = index",
 				"queryType": "PPL"
 			}`, from, to, 15*time.Second)
-			So(err.Error(), ShouldEqual, "response should have 2 fields but found 0")
+			So(err, ShouldBeNil)
 
 			So(c.multisearchRequests, ShouldHaveLength, 0)
 			So(c.pplRequest, ShouldHaveLength, 1)
 		})
 
+		Convey("With a PPL response that isn't a time series, should return the error of the query", func() {
+			c := newFakeClient(es.OpenSearch, "1.0.0")
+			res, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"query": "source = index",
+				"queryType": "PPL"
+			}`, from, to, 15*time.Second)
+			So(err, ShouldBeNil)
+
+			So(res.Responses[""].Error.Error(), ShouldEqual, "response should have at least 2 fields but found 0")
+			So(res.Responses[""].Status, ShouldEqual, backend.StatusBadRequest)
+		})
+
 		Convey("With multi piped PPL query string, should parse request correctly", func() {
 			c := newFakeClient(es.OpenSearch, "1.0.0")
 			_, err := executeTsdbQuery(c, `{
@@ -724,11 +1196,496 @@ func TestExecuteTimeSeriesQuery(t *testing.T) {
 				"query": "source = index | stats count(response) by timestamp",
 				"queryType": "PPL"
 			}`, from, to, 15*time.Second)
-			So(err.Error(), ShouldEqual, "response should have 2 fields but found 0")
+			So(err, ShouldBeNil)
 
 			// req := c.pplRequest[0]
 			// So(req.Query, ShouldEqual, "source = index | where `@timestamp` >= timestamp('2018-05-15 10:50:00') and `@timestamp` <= timestamp('2018-05-15 10:55:00') | stats count(response) by timestamp")
 		})
+
+		Convey("With SQL queries, should send SQL requests with the time range", func() {
+			c := newFakeClient(es.OpenSearch, "1.0.0")
+			c.sqlResponse = &es.PPLResponse{
+				Schema: []es.FieldSchema{
+					{Name: "timestamp", Type: "timestamp"},
+					{Name: "count(*)", Type: "integer"},
+				},
+				Datarows: []es.Datarow{{"2018-05-15 17:50:00", 10.0}},
+			}
+			res, err := executeTsdbQueries(c, from, to,
+				`{ "timeField": "@timestamp", "query": "SELECT timestamp, count(*) FROM logs GROUP BY timestamp", "queryType": "SQL" }`,
+				`{ "timeField": "@timestamp", "query": "SELECT * FROM logs WHERE level = 'error'", "queryType": "SQL", "format": "table" }`,
+			)
+			So(err, ShouldBeNil)
+
+			So(c.pplRequest, ShouldHaveLength, 0)
+			So(c.sqlRequest, ShouldHaveLength, 2)
+			queries := []string{c.sqlRequest[0].Query, c.sqlRequest[1].Query}
+			So(queries, ShouldContain, "SELECT timestamp, count(*) FROM logs WHERE `@timestamp` >= timestamp('2018-05-15 17:50:00') AND `@timestamp` <= timestamp('2018-05-15 17:55:00') GROUP BY timestamp")
+			So(queries, ShouldContain, "SELECT * FROM logs WHERE `@timestamp` >= timestamp('2018-05-15 17:50:00') AND `@timestamp` <= timestamp('2018-05-15 17:55:00') AND (level = 'error')")
+
+			So(res.Responses["A"].Frames, ShouldHaveLength, 1)
+			So(res.Responses["A"].Frames[0].Name, ShouldEqual, "count(*)")
+			So(res.Responses["B"].Frames, ShouldHaveLength, 1)
+			So(res.Responses["B"].Frames[0].Fields[0].Name, ShouldEqual, "timestamp")
+		})
+
+		Convey("With ad hoc filters, should add them to Lucene and PPL queries", func() {
+			c := newFakeClient(es.OpenSearch, "1.0.0")
+			_, err := executeTsdbQueries(c, from, to,
+				`{
+					"timeField": "@timestamp",
+					"query": "level:error",
+					"bucketAggs": [{ "type": "date_histogram", "field": "@timestamp", "id": "2" }],
+					"metrics": [{ "type": "count", "id": "1" }],
+					"adhocFilters": [{ "key": "host", "operator": "=", "value": "server1" }, { "key": "bytes", "operator": ">", "value": 10 }]
+				}`,
+				`{
+					"timeField": "@timestamp",
+					"query": "source = index",
+					"queryType": "PPL",
+					"format": "table",
+					"adhocFilters": [{ "key": "host", "operator": "!=", "value": "server1" }]
+				}`,
+			)
+			So(err, ShouldBeNil)
+
+			filters := c.multisearchRequests[0].Requests[0].Query.Bool.Filters
+			So(filters, ShouldHaveLength, 4)
+			So(filters[2], ShouldResemble, &es.MatchPhraseFilter{Key: "host", Value: "server1"})
+			So(filters[3], ShouldResemble, &es.RangeFilter{Key: "bytes", Gt: "10"})
+
+			So(c.pplRequest[0].Query, ShouldEndWith, " | where `host` != 'server1'")
+		})
+
+		Convey("With multi_terms, rare_terms and significant_terms aggs", func() {
+			c := newFakeClient(es.OpenSearch, "2.5.0")
+			_, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"bucketAggs": [
+					{ "type": "multi_terms", "id": "2", "settings": { "fields": ["region", "host"], "size": "10", "orderBy": "1", "order": "asc" } },
+					{ "type": "rare_terms", "field": "user", "id": "3", "settings": { "max_doc_count": 2 } },
+					{ "type": "significant_terms", "field": "error", "id": "4", "settings": { "size": 5 } }
+				],
+				"metrics": [{ "type": "avg", "field": "latency", "id": "1" }]
+			}`, from, to, 15*time.Second)
+			So(err, ShouldBeNil)
+
+			multiTermsAgg := c.multisearchRequests[0].Requests[0].Aggs[0]
+			So(multiTermsAgg.Aggregation.Type, ShouldEqual, "multi_terms")
+			multiTerms := multiTermsAgg.Aggregation.Aggregation.(*es.MultiTermsAggregation)
+			So(multiTerms.Terms, ShouldResemble, []es.MultiTermsSource{{Field: "region"}, {Field: "host"}})
+			So(multiTerms.Size, ShouldEqual, 10)
+			So(multiTerms.Order, ShouldResemble, map[string]interface{}{"1": "asc"})
+			So(multiTermsAgg.Aggregation.Aggs[0].Key, ShouldEqual, "1")
+
+			rareTermsAgg := multiTermsAgg.Aggregation.Aggs[1]
+			So(rareTermsAgg.Aggregation.Type, ShouldEqual, "rare_terms")
+			So(rareTermsAgg.Aggregation.Aggregation.(*es.RareTermsAggregation).MaxDocCount, ShouldEqual, 2)
+
+			significantTermsAgg := rareTermsAgg.Aggregation.Aggs[0]
+			So(significantTermsAgg.Aggregation.Type, ShouldEqual, "significant_terms")
+			significantTerms := significantTermsAgg.Aggregation.Aggregation.(*es.SignificantTermsAggregation)
+			So(significantTerms.Field, ShouldEqual, "error")
+			So(significantTerms.Size, ShouldEqual, 5)
+		})
+
+		Convey("With a multi_terms agg with a single field", func() {
+			c := newFakeClient(es.OpenSearch, "2.5.0")
+			res, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"bucketAggs": [{ "type": "multi_terms", "field": "host", "id": "2" }],
+				"metrics": [{ "type": "count", "id": "1" }]
+			}`, from, to, 15*time.Second)
+			So(err, ShouldBeNil)
+
+			So(res.Responses[""].Error.Error(), ShouldEqual, "the multi_terms aggregation 2 needs at least two fields")
+			So(c.multisearchRequests, ShouldBeEmpty)
+		})
+
+		Convey("With a composite agg", func() {
+			compositeResponse := func(afterKey string, customers ...string) *es.MultiSearchResponse {
+				buckets := make([]interface{}, 0)
+				for _, customer := range customers {
+					buckets = append(buckets, map[string]interface{}{
+						"key":       map[string]interface{}{"customer": customer},
+						"doc_count": 1,
+						"1":         map[string]interface{}{"value": 10},
+					})
+				}
+				composite := map[string]interface{}{"buckets": buckets}
+				if afterKey != "" {
+					composite["after_key"] = map[string]interface{}{"customer": afterKey}
+				}
+				return &es.MultiSearchResponse{
+					Responses: []*es.SearchResponse{{Aggregations: map[string]interface{}{"2": composite}}},
+				}
+			}
+			compositeQuery := func(settings string) string {
+				return fmt.Sprintf(`{
+					"timeField": "@timestamp",
+					"bucketAggs": [{ "type": "composite", "field": "customer", "id": "2", "settings": %s }],
+					"metrics": [{ "type": "sum", "field": "amount", "id": "1" }]
+				}`, settings)
+			}
+
+			Convey("Should page through the buckets with the after key", func() {
+				c := newFakeClient(es.OpenSearch, "2.5.0")
+				c.multiSearchResponse = compositeResponse("b", "a", "b")
+				c.multiSearchPages = []*es.MultiSearchResponse{
+					compositeResponse("d", "c", "d"),
+					compositeResponse("e", "e"),
+				}
+				res, err := executeTsdbQuery(c, compositeQuery(`{ "size": 2 }`), from, to, 15*time.Second)
+				So(err, ShouldBeNil)
+
+				So(c.multisearchRequests, ShouldHaveLength, 3)
+				composite := c.multisearchRequests[0].Requests[0].Aggs[0].Aggregation.Aggregation.(*es.CompositeAggregation)
+				So(composite.Size, ShouldEqual, 2)
+				So(composite.Sources, ShouldResemble, []es.CompositeSource{{Name: "customer", Type: "terms", Field: "customer"}})
+				So(composite.After, ShouldBeNil)
+				for i, afterKey := range []string{"b", "d"} {
+					pageComposite := c.multisearchRequests[i+1].Requests[0].Aggs[0].Aggregation.Aggregation.(*es.CompositeAggregation)
+					So(pageComposite.Size, ShouldEqual, 2)
+					So(pageComposite.After, ShouldResemble, map[string]interface{}{"customer": afterKey})
+				}
+
+				frames := res.Responses[""].Frames
+				So(frames, ShouldHaveLength, 1)
+				So(frames[0].Rows(), ShouldEqual, 5)
+				So(frames[0].Fields[0].Name, ShouldEqual, "customer")
+				So(*frames[0].Fields[0].At(4).(*string), ShouldEqual, "e")
+				So(frames[0].Meta, ShouldBeNil)
+			})
+
+			Convey("Should stop at the bucket limit", func() {
+				c := newFakeClient(es.OpenSearch, "2.5.0")
+				c.multiSearchResponse = compositeResponse("b", "a", "b")
+				c.multiSearchPages = []*es.MultiSearchResponse{compositeResponse("d", "c", "d")}
+				res, err := executeTsdbQuery(c, compositeQuery(`{ "size": 2, "maxBuckets": 3 }`), from, to, 15*time.Second)
+				So(err, ShouldBeNil)
+
+				So(c.multisearchRequests, ShouldHaveLength, 2)
+				frames := res.Responses[""].Frames
+				So(frames, ShouldHaveLength, 1)
+				So(frames[0].Rows(), ShouldEqual, 3)
+				So(frames[0].Meta.Notices, ShouldHaveLength, 1)
+				So(frames[0].Meta.Notices[0].Text, ShouldEqual, "Showing the first 3 buckets, increase the bucket limit of the composite aggregation to show more")
+			})
+
+			Convey("Should only report the bucket limit when buckets are left", func() {
+				c := newFakeClient(es.OpenSearch, "2.5.0")
+				c.multiSearchResponse = compositeResponse("b", "a", "b")
+				c.multiSearchPages = []*es.MultiSearchResponse{
+					compositeResponse("d", "c", "d"),
+					compositeResponse(""),
+				}
+				res, err := executeTsdbQuery(c, compositeQuery(`{ "size": 2, "maxBuckets": 4 }`), from, to, 15*time.Second)
+				So(err, ShouldBeNil)
+
+				So(c.multisearchRequests, ShouldHaveLength, 3)
+				probe := c.multisearchRequests[2].Requests[0].Aggs[0].Aggregation.Aggregation.(*es.CompositeAggregation)
+				So(probe.Size, ShouldEqual, 1)
+				So(probe.After, ShouldResemble, map[string]interface{}{"customer": "d"})
+
+				frames := res.Responses[""].Frames
+				So(frames, ShouldHaveLength, 1)
+				So(frames[0].Rows(), ShouldEqual, 4)
+				So(frames[0].Meta, ShouldBeNil)
+			})
+
+			Convey("Should only fail the query whose pages can't be fetched", func() {
+				c := newFakeClient(es.OpenSearch, "2.5.0")
+				c.multiSearchResponse = compositeResponse("b", "a", "b")
+				c.multiSearchResponse.Responses = append(c.multiSearchResponse.Responses, &es.SearchResponse{
+					Hits: &es.SearchResponseHits{Hits: []map[string]interface{}{{"_id": "1", "_source": map[string]interface{}{"customer": "a"}}}},
+				})
+				c.multiSearchPages = []*es.MultiSearchResponse{{Status: 500}}
+				res, err := executeTsdbQueries(c, from, to,
+					compositeQuery(`{ "size": 2 }`),
+					`{ "timeField": "@timestamp", "metrics": [{ "type": "raw_data", "id": "1" }] }`,
+				)
+				So(err, ShouldBeNil)
+
+				So(c.multisearchRequests, ShouldHaveLength, 2)
+				So(res.Responses["A"].Error.Error(), ShouldEqual, "multisearch request failed with status 500")
+				So(res.Responses["A"].Status, ShouldEqual, backend.StatusBadGateway)
+				So(res.Responses["B"].Error, ShouldBeNil)
+				So(res.Responses["B"].Frames, ShouldHaveLength, 1)
+			})
+
+			Convey("Should return an error for sibling pipelines of the composite agg", func() {
+				c := newFakeClient(es.OpenSearch, "2.5.0")
+				res, err := executeTsdbQuery(c, `{
+					"timeField": "@timestamp",
+					"bucketAggs": [{ "type": "composite", "field": "customer", "id": "2" }],
+					"metrics": [
+						{ "type": "sum", "field": "amount", "id": "1" },
+						{ "type": "sum_bucket", "field": "1", "pipelineAgg": "1", "id": "3" }
+					]
+				}`, from, to, 15*time.Second)
+				So(err, ShouldBeNil)
+
+				So(res.Responses[""].Error.Error(), ShouldEqual, "the sum_bucket metric 3 can't aggregate the buckets of the composite aggregation 2, which are fetched in pages")
+				So(c.multisearchRequests, ShouldBeEmpty)
+			})
+
+			Convey("Should return an error when it isn't the first bucket agg", func() {
+				c := newFakeClient(es.OpenSearch, "2.5.0")
+				res, err := executeTsdbQuery(c, `{
+					"timeField": "@timestamp",
+					"bucketAggs": [
+						{ "type": "terms", "field": "region", "id": "3" },
+						{ "type": "composite", "field": "customer", "id": "2" }
+					],
+					"metrics": [{ "type": "count", "id": "1" }]
+				}`, from, to, 15*time.Second)
+				So(err, ShouldBeNil)
+
+				So(res.Responses[""].Error.Error(), ShouldEqual, "the composite aggregation 2 should be the first bucket aggregation")
+				So(c.multisearchRequests, ShouldBeEmpty)
+			})
+		})
+
+		Convey("With a raw DSL query", func() {
+			c := newFakeClient(es.OpenSearch, "1.0.0")
+			c.multiSearchResponse = &es.MultiSearchResponse{
+				Responses: []*es.SearchResponse{
+					{
+						Aggregations: map[string]interface{}{
+							"2": map[string]interface{}{
+								"buckets": []interface{}{
+									map[string]interface{}{"key": 1526406600000, "doc_count": 10},
+									map[string]interface{}{"key": 1526406900000, "doc_count": 15},
+								},
+							},
+						},
+					},
+				},
+			}
+			res, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"luceneQueryType": "RawDSL",
+				"query": "{ \"query\": { \"function_score\": { \"query\": { \"match_all\": {} }, \"random_score\": {} } }, \"timeout\": \"$__interval\" }",
+				"bucketAggs": [{ "type": "date_histogram", "field": "@timestamp", "id": "2" }],
+				"metrics": [{ "type": "count", "id": "1" }]
+			}`, from, to, 15*time.Second)
+			So(err, ShouldBeNil)
+
+			sr := c.multisearchRequests[0].Requests[0]
+			So(sr.Query.Bool.Filters, ShouldHaveLength, 1)
+			So(sr.Query.Bool.Filters[0].(*es.RangeFilter).Key, ShouldEqual, "@timestamp")
+			So(sr.Query.Bool.Must, ShouldHaveLength, 1)
+			So(sr.Query.Bool.Must[0], ShouldResemble, map[string]interface{}{
+				"function_score": map[string]interface{}{
+					"query":        map[string]interface{}{"match_all": map[string]interface{}{}},
+					"random_score": map[string]interface{}{},
+				},
+			})
+			// replaced by the interval when the request is encoded
+			So(sr.CustomProps["timeout"], ShouldEqual, "$__interval")
+			So(sr.Aggs[0].Key, ShouldEqual, "2")
+
+			So(res.Responses[""].Error, ShouldBeNil)
+			So(res.Responses[""].Frames, ShouldHaveLength, 1)
+			So(res.Responses[""].Frames[0].Rows(), ShouldEqual, 2)
+		})
+
+		Convey("With invalid raw DSL queries, should return the errors of those queries", func() {
+			c := newFakeClient(es.OpenSearch, "1.0.0")
+			res, err := executeTsdbQueries(c, from, to,
+				`{ "timeField": "@timestamp", "luceneQueryType": "RawDSL", "query": "{ \"query\": " }`,
+				`{ "timeField": "@timestamp", "luceneQueryType": "RawDSL", "query": "{ \"aggs\": {} }" }`,
+				`{ "timeField": "@timestamp", "luceneQueryType": "RawDSL", "query": "{ \"query\": \"level:error\" }" }`,
+				`{ "timeField": "@timestamp", "luceneQueryType": "RawDSL", "query": "{ \"size\": 10000 }" }`,
+				`{ "timeField": "@timestamp", "luceneQueryType": "RawDSL", "query": "{ \"sort\": [\"_doc\"] }" }`,
+			)
+			So(err, ShouldBeNil)
+
+			So(res.Responses["A"].Error.Error(), ShouldStartWith, "invalid raw DSL query: ")
+			So(res.Responses["B"].Error.Error(), ShouldEqual, "invalid raw DSL query: aggs are defined by the metrics and bucket aggregations of the query")
+			So(res.Responses["C"].Error.Error(), ShouldEqual, "invalid raw DSL query: the query property should be a JSON object")
+			So(res.Responses["D"].Error.Error(), ShouldEqual, "invalid raw DSL query: the size is defined by the metric of the query")
+			So(res.Responses["E"].Error.Error(), ShouldEqual, "invalid raw DSL query: the sort is defined by the metric of the query")
+			for _, refID := range []string{"A", "B", "C", "D", "E"} {
+				So(res.Responses[refID].Status, ShouldEqual, backend.StatusBadRequest)
+			}
+			So(c.multisearchRequests, ShouldBeEmpty)
+		})
+
+		Convey("With an unsupported ad hoc filter operator, should return the error of the query", func() {
+			c := newFakeClient(es.OpenSearch, "1.0.0")
+			res, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"query": "source = index",
+				"queryType": "PPL",
+				"adhocFilters": [{ "key": "host", "operator": "<>", "value": "server1" }]
+			}`, from, to, 15*time.Second)
+			So(err, ShouldBeNil)
+
+			So(res.Responses[""].Error.Error(), ShouldEqual, `unsupported ad hoc filter operator "<>"`)
+			So(res.Responses[""].Status, ShouldEqual, backend.StatusBadRequest)
+			So(c.pplRequest, ShouldBeEmpty)
+		})
+
+		Convey("With a paginated PPL response", func() {
+			c := newFakeClient(es.OpenSearch, "1.0.0")
+			c.pplResponse = &es.PPLResponse{
+				Schema:   []es.FieldSchema{{Name: "host", Type: "string"}},
+				Datarows: []es.Datarow{{"server1"}, {"server2"}},
+				Total:    5,
+				Cursor:   "page2",
+			}
+			c.pages = map[string]*es.PPLResponse{
+				"page2": {Datarows: []es.Datarow{{"server3"}, {"server4"}}, Cursor: "page3"},
+				"page3": {Datarows: []es.Datarow{{"server5"}}},
+			}
+
+			Convey("Should fetch all the pages", func() {
+				res, err := executeTsdbQuery(c, `{
+					"timeField": "@timestamp",
+					"query": "source = index",
+					"queryType": "PPL",
+					"format": "table"
+				}`, from, to, 15*time.Second)
+				So(err, ShouldBeNil)
+
+				So(c.pplRequest[0].FetchSize, ShouldEqual, pplPageSize)
+				frame := res.Responses[""].Frames[0]
+				So(frame.Rows(), ShouldEqual, 5)
+				So(frame.Meta, ShouldBeNil)
+				So(c.closedCursors, ShouldBeEmpty)
+			})
+
+			Convey("Should stop at the row limit of the query and add a notice", func() {
+				res, err := executeTsdbQuery(c, `{
+					"timeField": "@timestamp",
+					"query": "source = index",
+					"queryType": "PPL",
+					"format": "table",
+					"maxRows": 3
+				}`, from, to, 15*time.Second)
+				So(err, ShouldBeNil)
+
+				So(c.pplRequest[0].FetchSize, ShouldEqual, 3)
+				frame := res.Responses[""].Frames[0]
+				So(frame.Rows(), ShouldEqual, 3)
+				So(frame.Meta.Notices, ShouldHaveLength, 1)
+				So(frame.Meta.Notices[0].Severity, ShouldEqual, data.NoticeSeverityWarning)
+				So(frame.Meta.Notices[0].Text, ShouldEqual, "Showing the first 3 rows of 5, increase the row limit of the query to show more")
+				So(c.closedCursors, ShouldResemble, []string{"page3"})
+			})
+
+			Convey("Should return the error of a page", func() {
+				c.pages["page2"] = &es.PPLResponse{Status: 400, Error: map[string]interface{}{"reason": "invalid cursor"}}
+				res, err := executeTsdbQuery(c, `{
+					"timeField": "@timestamp",
+					"query": "source = index",
+					"queryType": "PPL",
+					"format": "table"
+				}`, from, to, 15*time.Second)
+				So(err, ShouldBeNil)
+
+				So(res.Responses[""].Error.Error(), ShouldEqual, "failed to fetch the next page of the response: invalid cursor")
+				So(res.Responses[""].Status, ShouldEqual, backend.StatusBadGateway)
+			})
+		})
+
+		Convey("With an invalid row limit, should return the error of the query", func() {
+			c := newFakeClient(es.OpenSearch, "1.0.0")
+			res, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"query": "SELECT * FROM logs",
+				"queryType": "SQL",
+				"maxRows": 0
+			}`, from, to, 15*time.Second)
+			So(err, ShouldBeNil)
+
+			So(res.Responses[""].Error.Error(), ShouldEqual, "invalid row limit 0, the row limit should be greater than 0")
+			So(res.Responses[""].Status, ShouldEqual, backend.StatusBadRequest)
+			So(c.sqlRequest, ShouldBeEmpty)
+		})
+
+		Convey("With invalid queries, should return the errors of those queries only", func() {
+			c := newFakeClient(es.OpenSearch, "1.0.0")
+			c.pplResponse = &es.PPLResponse{
+				Schema:   []es.FieldSchema{{Name: "host", Type: "string"}},
+				Datarows: []es.Datarow{{"server1"}},
+			}
+			res, err := executeTsdbQueries(c, from, to,
+				`{ "timeField": "@timestamp", "query": "source = index", "queryType": "PPL", "format": "table" }`,
+				`{ "query": "source = index", "queryType": "PPL", "format": "table" }`,
+				`{ "timeField": "@timestamp", "queryType": "unknown" }`,
+			)
+			So(err, ShouldBeNil)
+
+			So(res.Responses["A"].Error, ShouldBeNil)
+			So(res.Responses["A"].Frames, ShouldHaveLength, 1)
+			So(res.Responses["B"].Error.Error(), ShouldEqual, "the query has no time field")
+			So(res.Responses["B"].Status, ShouldEqual, backend.StatusBadRequest)
+			So(res.Responses["C"].Error.Error(), ShouldEqual, `unsupported query type "unknown"`)
+			So(res.Responses["C"].Status, ShouldEqual, backend.StatusBadRequest)
+			So(c.pplRequest, ShouldHaveLength, 1)
+		})
+
+		Convey("With failed requests, should return the error for each query of the requests", func() {
+			c := newFakeClient(es.OpenSearch, "1.0.0")
+			c.multiSearchError = errors.New("connection refused")
+			res, err := executeTsdbQueries(c, from, to,
+				`{ "timeField": "@timestamp", "bucketAggs": [{ "type": "date_histogram", "field": "@timestamp", "id": "2" }], "metrics": [{ "type": "count", "id": "1" }] }`,
+				`{ "timeField": "@timestamp", "metrics": [{ "type": "raw_data", "id": "1" }] }`,
+				`{ "timeField": "@timestamp", "query": "source = index", "queryType": "PPL", "format": "table" }`,
+			)
+			So(err, ShouldBeNil)
+
+			So(res.Responses, ShouldHaveLength, 3)
+			for _, refID := range []string{"A", "B", "C"} {
+				So(res.Responses[refID].Error.Error(), ShouldEqual, "connection refused")
+				So(res.Responses[refID].Status, ShouldEqual, backend.StatusBadGateway)
+			}
+		})
+
+		Convey("With an error response of the cluster, should return the status of the error", func() {
+			c := newFakeClient(es.OpenSearch, "1.0.0")
+			c.pplResponse = &es.PPLResponse{
+				Status: 400,
+				Error:  map[string]interface{}{"reason": "Syntax Error"},
+			}
+			res, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"query": "source = index | where",
+				"queryType": "PPL"
+			}`, from, to, 15*time.Second)
+			So(err, ShouldBeNil)
+
+			So(res.Responses[""].Error.Error(), ShouldEqual, "Syntax Error")
+			So(res.Responses[""].Status, ShouldEqual, backend.StatusBadRequest)
+		})
+
+		Convey("With PPL and Lucene queries, should execute all of them", func() {
+			c := newFakeClient(es.OpenSearch, "1.0.0")
+			c.pplResponse = &es.PPLResponse{
+				Schema:   []es.FieldSchema{{Name: "host", Type: "string"}},
+				Datarows: []es.Datarow{{"server1"}},
+			}
+			c.multiSearchResponse = &es.MultiSearchResponse{
+				Responses: []*es.SearchResponse{{Aggregations: map[string]interface{}{}}},
+			}
+			res, err := executeTsdbQueries(c, from, to,
+				`{ "timeField": "@timestamp", "query": "source = index", "queryType": "PPL", "format": "table" }`,
+				`{ "timeField": "@timestamp", "query": "source = index | head 1", "queryType": "PPL", "format": "table" }`,
+				`{ "timeField": "@timestamp", "query": "source = index | head 2", "queryType": "PPL", "format": "table" }`,
+				`{ "timeField": "@timestamp", "bucketAggs": [{ "type": "date_histogram", "field": "@timestamp", "id": "2" }], "metrics": [{ "type": "count", "id": "1" }] }`,
+			)
+			So(err, ShouldBeNil)
+
+			So(c.pplRequest, ShouldHaveLength, 3)
+			So(c.multisearchRequests, ShouldHaveLength, 1)
+			So(res.Responses, ShouldHaveLength, 4)
+			for _, refID := range []string{"A", "B", "C"} {
+				So(res.Responses[refID].Frames, ShouldHaveLength, 1)
+				So(res.Responses[refID].Frames[0].Fields[0].Name, ShouldEqual, "host")
+			}
+		})
 	})
 }
 
@@ -738,12 +1695,23 @@ type fakeClient struct {
 	timeField           string
 	index               string
 	multiSearchResponse *es.MultiSearchResponse
+	// the responses of the multisearch requests after the first one
+	multiSearchPages    []*es.MultiSearchResponse
 	multiSearchError    error
 	builder             *es.MultiSearchRequestBuilder
 	pplbuilder          *es.PPLRequestBuilder
 	multisearchRequests []*es.MultiSearchRequest
 	pplRequest          []*es.PPLRequest
 	pplResponse         *es.PPLResponse
+	sqlRequest          []*es.SQLRequest
+	sqlResponse         *es.PPLResponse
+	pages               map[string]*es.PPLResponse
+	closedCursors       []string
+	clusterInfo         *es.ClusterInfo
+	mapping             map[string]interface{}
+	requestError        error
+	// the handlers execute their requests concurrently
+	mu sync.Mutex
 }
 
 func newFakeClient(flavor es.Flavor, versionString string) *fakeClient {
@@ -758,6 +1726,8 @@ func newFakeClient(flavor es.Flavor, versionString string) *fakeClient {
 		multiSearchResponse: &es.MultiSearchResponse{},
 		pplRequest:          make([]*es.PPLRequest, 0),
 		pplResponse:         &es.PPLResponse{},
+		sqlRequest:          make([]*es.SQLRequest, 0),
+		sqlResponse:         &es.PPLResponse{},
 	}
 }
 
@@ -784,7 +1754,12 @@ func (c *fakeClient) GetMinInterval(queryInterval string) (time.Duration, error)
 }
 
 func (c *fakeClient) ExecuteMultisearch(r *es.MultiSearchRequest) (*es.MultiSearchResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.multisearchRequests = append(c.multisearchRequests, r)
+	if page := len(c.multisearchRequests) - 2; page >= 0 && page < len(c.multiSearchPages) {
+		return c.multiSearchPages[page], c.multiSearchError
+	}
 	return c.multiSearchResponse, c.multiSearchError
 }
 
@@ -794,6 +1769,8 @@ func (c *fakeClient) MultiSearch() *es.MultiSearchRequestBuilder {
 }
 
 func (c *fakeClient) ExecutePPLQuery(r *es.PPLRequest) (*es.PPLResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.pplRequest = append(c.pplRequest, r)
 	return c.pplResponse, c.multiSearchError
 }
@@ -803,14 +1780,40 @@ func (c *fakeClient) PPL() *es.PPLRequestBuilder {
 	return c.pplbuilder
 }
 
-func newTsdbQuery(body string) (*backend.QueryDataRequest, error) {
-	return &backend.QueryDataRequest{
-		Queries: []backend.DataQuery{
-			{
-				JSON: []byte(body),
-			},
-		},
-	}, nil
+func (c *fakeClient) ExecuteSQLQuery(r *es.SQLRequest) (*es.PPLResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sqlRequest = append(c.sqlRequest, r)
+	return c.sqlResponse, c.multiSearchError
+}
+
+func (c *fakeClient) SQL() *es.SQLRequestBuilder {
+	return es.NewSQLRequestBuilder(c.GetIndex())
+}
+
+func (c *fakeClient) FetchNextPage(language es.QueryLanguage, cursor string) (*es.PPLResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	page, ok := c.pages[cursor]
+	if !ok {
+		return nil, fmt.Errorf("unknown cursor %q", cursor)
+	}
+	return page, c.requestError
+}
+
+func (c *fakeClient) CloseCursor(language es.QueryLanguage, cursor string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closedCursors = append(c.closedCursors, cursor)
+	return nil
+}
+
+func (c *fakeClient) GetClusterInfo() (*es.ClusterInfo, error) {
+	return c.clusterInfo, c.requestError
+}
+
+func (c *fakeClient) GetMapping() (map[string]interface{}, error) {
+	return c.mapping, c.requestError
 }
 
 func executeTsdbQuery(c es.Client, body string, from, to time.Time, minInterval time.Duration) (*backend.QueryDataResponse, error) {
@@ -829,6 +1832,23 @@ func executeTsdbQuery(c es.Client, body string, from, to time.Time, minInterval
 	return query.execute()
 }
 
+// executeTsdbQueries executes the queries with the RefIDs A, B, C...
+func executeTsdbQueries(c es.Client, from, to time.Time, bodies ...string) (*backend.QueryDataResponse, error) {
+	tsdbQuery := &backend.QueryDataRequest{}
+	for i, body := range bodies {
+		tsdbQuery.Queries = append(tsdbQuery.Queries, backend.DataQuery{
+			RefID: string(rune('A' + i)),
+			JSON:  []byte(body),
+			TimeRange: backend.TimeRange{
+				From: from,
+				To:   to,
+			},
+		})
+	}
+	query := newTimeSeriesQuery(c, tsdbQuery, tsdb.NewIntervalCalculator(&tsdb.IntervalOptions{MinInterval: 15 * time.Second}))
+	return query.execute()
+}
+
 func TestTimeSeriesQueryParser(t *testing.T) {
 	Convey("Test time series query parser", t, func() {
 		p := newTimeSeriesQueryParser()
@@ -883,13 +1903,8 @@ func TestTimeSeriesQueryParser(t *testing.T) {
 					}
 				]
 			}`
-			tsdbQuery, err := newTsdbQuery(body)
+			q, err := p.parseQuery(backend.DataQuery{JSON: []byte(body)})
 			So(err, ShouldBeNil)
-			queries, err := p.parse(tsdbQuery)
-			So(err, ShouldBeNil)
-			So(queries, ShouldHaveLength, 1)
-
-			q := queries[0]
 
 			So(q.TimeField, ShouldEqual, "@timestamp")
 			So(q.RawQuery, ShouldEqual, "@metric:cpu")
@@ -933,13 +1948,8 @@ func TestTimeSeriesQueryParser(t *testing.T) {
 				"timeField": "@timestamp",
 				"query": "*"
 			}`
-			tsdbQuery, err := newTsdbQuery(body)
+			q, err := p.parseQuery(backend.DataQuery{JSON: []byte(body)})
 			So(err, ShouldBeNil)
-			queries, err := p.parse(tsdbQuery)
-			So(err, ShouldBeNil)
-			So(queries, ShouldHaveLength, 1)
-
-			q := queries[0]
 
 			So(q.TimeField, ShouldEqual, "@timestamp")
 			So(q.RawQuery, ShouldEqual, "*")
@@ -952,13 +1962,8 @@ func TestTimeSeriesQueryParser(t *testing.T) {
 				"query": "source=index",
 				"queryType": "PPL"
 			}`
-			tsdbQuery, err := newTsdbQuery(body)
+			q, err := p.parseQuery(backend.DataQuery{JSON: []byte(body)})
 			So(err, ShouldBeNil)
-			queries, err := p.parse(tsdbQuery)
-			So(err, ShouldBeNil)
-			So(queries, ShouldHaveLength, 1)
-
-			q := queries[0]
 
 			So(q.TimeField, ShouldEqual, "@timestamp")
 			So(q.RawQuery, ShouldEqual, "source=index")
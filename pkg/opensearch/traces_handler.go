@@ -0,0 +1,119 @@
+package opensearch
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	es "github.com/grafana/opensearch-datasource/pkg/opensearch/client"
+	"github.com/grafana/opensearch-datasource/pkg/tsdb"
+)
+
+const (
+	traceListSize  = 100
+	traceSpansSize = 1000
+)
+
+// traceLatencyScript is the script used by the OpenSearch trace analytics dashboard to get the trace latency in ms
+const traceLatencyScript = `
+                if (doc.containsKey('traceGroupFields.durationInNanos') && !doc['traceGroupFields.durationInNanos'].empty) {
+                  return Math.round(doc['traceGroupFields.durationInNanos'].value / 10000) / 100.0
+                }
+                return 0
+                `
+
+type tracesHandler struct {
+	client  es.Client
+	req     *backend.QueryDataRequest
+	ms      *es.MultiSearchRequestBuilder
+	queries []*Query
+}
+
+var newTracesHandler = func(client es.Client, req *backend.QueryDataRequest) *tracesHandler {
+	return &tracesHandler{
+		client:  client,
+		req:     req,
+		ms:      client.MultiSearch(),
+		queries: make([]*Query, 0),
+	}
+}
+
+func (h *tracesHandler) processQuery(q *Query) error {
+	fromMs := h.req.Queries[0].TimeRange.From.UnixNano() / int64(time.Millisecond)
+	toMs := h.req.Queries[0].TimeRange.To.UnixNano() / int64(time.Millisecond)
+	from := fmt.Sprintf("%d", fromMs)
+	to := fmt.Sprintf("%d", toMs)
+
+	h.queries = append(h.queries, q)
+
+	b := h.ms.Search(tsdb.Interval{})
+	filters := b.Query().Bool().Filter()
+	filters.AddDateRangeFilter("startTime", to, from, es.DateFormatEpochMS)
+
+	if traceID := getTraceID(q.RawQuery); traceID != "" {
+		b.Size(traceSpansSize)
+		filters.AddTermFilter("traceId", traceID)
+		return nil
+	}
+
+	b.Size(0)
+	filters.AddQueryStringFilter(q.RawQuery, true)
+	filters.AddAdHocFilters(q.AdHocFilters)
+
+	// one bucket per trace with the aggregations shown in the trace list
+	b.Agg().Terms("traces", "traceId", func(a *es.TermsAggregation, b es.AggBuilder) {
+		a.Size = traceListSize
+		a.Order["_key"] = "asc"
+
+		b.Metric("latency", "max", "", func(a *es.MetricAggregation) {
+			a.Settings["script"] = map[string]interface{}{
+				"source": traceLatencyScript,
+				"lang":   "painless",
+			}
+		})
+		b.Terms("trace_group", "traceGroup", func(a *es.TermsAggregation, b es.AggBuilder) {
+			a.Size = 1
+		})
+		b.Filter("error_count", &es.TermFilter{Key: "traceGroupFields.statusCode", Value: "2"}, nil)
+		b.Metric("last_updated", "max", "traceGroupFields.endTime", nil)
+	})
+
+	return nil
+}
+
+func (h *tracesHandler) executeQueries() (*backend.QueryDataResponse, error) {
+	if len(h.queries) == 0 {
+		return nil, nil
+	}
+
+	req, err := h.ms.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := h.client.ExecuteMultisearch(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.Status >= http.StatusBadRequest {
+		return nil, fmt.Errorf("multisearch request failed with status %d", res.Status)
+	}
+
+	rp := newTracesResponseParser(res.Responses, h.queries, h.req.PluginContext.DataSourceInstanceSettings)
+	return rp.parse(), nil
+}
+
+var traceIDPatternRegex = regexp.MustCompile(`traceId:(.*)$`)
+
+// getTraceID returns the trace id of a `traceId: <id>` query, which requests the spans of a single
+// trace. Any other query lists the traces that match it.
+func getTraceID(query string) string {
+	matches := traceIDPatternRegex.FindStringSubmatch(query)
+	if len(matches) < 2 {
+		return ""
+	}
+	return strings.TrimSpace(matches[1])
+}
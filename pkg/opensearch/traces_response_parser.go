@@ -0,0 +1,247 @@
+package opensearch
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	es "github.com/grafana/opensearch-datasource/pkg/opensearch/client"
+	"github.com/grafana/opensearch-datasource/pkg/utils"
+)
+
+type tracesResponseParser struct {
+	Responses []*es.SearchResponse
+	Targets   []*Query
+	DsInfo    *backend.DataSourceInstanceSettings
+}
+
+var newTracesResponseParser = func(responses []*es.SearchResponse, targets []*Query, dsInfo *backend.DataSourceInstanceSettings) *tracesResponseParser {
+	return &tracesResponseParser{
+		Responses: responses,
+		Targets:   targets,
+		DsInfo:    dsInfo,
+	}
+}
+
+// traceKeyValuePair and traceLog are the shapes the Grafana trace view expects in its JSON fields
+type traceKeyValuePair struct {
+	Key   string      `json:"key"`
+	Value interface{} `json:"value"`
+}
+
+type traceLog struct {
+	Timestamp float64             `json:"timestamp"`
+	Fields    []traceKeyValuePair `json:"fields"`
+}
+
+func (rp *tracesResponseParser) parse() *backend.QueryDataResponse {
+	result := backend.NewQueryDataResponse()
+
+	for i, res := range rp.Responses {
+		if i >= len(rp.Targets) {
+			break
+		}
+		target := rp.Targets[i]
+
+		if res.Error != nil {
+			result.Responses[target.RefID] = backend.DataResponse{
+				Error:  getErrorFromOpenSearchResponse(res),
+				Status: responseErrorStatus(res.Status),
+			}
+			continue
+		}
+
+		var frame *data.Frame
+		if getTraceID(target.RawQuery) != "" {
+			frame = processTraceSpansResponse(res)
+		} else {
+			frame = rp.processTraceListResponse(res)
+		}
+		frame.RefID = target.RefID
+
+		result.Responses[target.RefID] = backend.DataResponse{
+			Frames: data.Frames{frame},
+		}
+	}
+
+	return result
+}
+
+func (rp *tracesResponseParser) processTraceListResponse(res *es.SearchResponse) *data.Frame {
+	buckets := utils.NewJsonFromAny(res.Aggregations).GetPath("traces", "buckets").MustArray()
+
+	traceIDs := make([]string, len(buckets))
+	traceGroups := make([]string, len(buckets))
+	latencies := make([]*float64, len(buckets))
+	errorCounts := make([]float64, len(buckets))
+	lastUpdated := make([]*time.Time, len(buckets))
+
+	for i, b := range buckets {
+		bucket := utils.NewJsonFromAny(b)
+		traceIDs[i] = bucket.Get("key").MustString()
+		traceGroups[i] = bucket.GetPath("trace_group", "buckets").GetIndex(0).Get("key").MustString()
+		if latency := castToNullFloat(bucket.GetPath("latency", "value")); latency.Valid {
+			latencies[i] = &latency.Float64
+		}
+		errorCounts[i] = bucket.GetPath("error_count", "doc_count").MustFloat64()
+		lastUpdated[i] = utils.NullFloatToNullableTime(castToNullFloat(bucket.GetPath("last_updated", "value")))
+	}
+
+	traceIDField := data.NewField("Trace Id", nil, traceIDs)
+	traceIDField.Config = &data.FieldConfig{
+		Links: []data.DataLink{rp.getTraceLink()},
+	}
+
+	frame := data.NewFrame("Traces",
+		traceIDField,
+		data.NewField("Trace Group", nil, traceGroups),
+		data.NewField("Latency (ms)", nil, latencies),
+		data.NewField("Error Count", nil, errorCounts),
+		data.NewField("Last Updated", nil, lastUpdated),
+	)
+	frame.Meta = &data.FrameMeta{
+		PreferredVisualization: data.VisTypeTable,
+	}
+	return frame
+}
+
+// getTraceLink returns an internal link that opens the spans of the trace in the same datasource
+func (rp *tracesResponseParser) getTraceLink() data.DataLink {
+	link := data.DataLink{
+		Title: "Trace: ${__value.raw}",
+		Internal: &data.InternalDataLink{
+			Query: map[string]interface{}{
+				"query":           "traceId: ${__value.raw}",
+				"luceneQueryType": luceneQueryTypeTraces,
+			},
+		},
+	}
+
+	if rp.DsInfo != nil {
+		link.Internal.DatasourceUID = rp.DsInfo.UID
+		link.Internal.DatasourceName = rp.DsInfo.Name
+		link.Internal.Query.(map[string]interface{})["datasource"] = map[string]interface{}{
+			"uid": rp.DsInfo.UID,
+		}
+	}
+
+	return link
+}
+
+func processTraceSpansResponse(res *es.SearchResponse) *data.Frame {
+	var hits []map[string]interface{}
+	if res.Hits != nil {
+		hits = res.Hits.Hits
+	}
+
+	traceIDs := make([]string, len(hits))
+	spanIDs := make([]string, len(hits))
+	parentSpanIDs := make([]string, len(hits))
+	operationNames := make([]string, len(hits))
+	serviceNames := make([]string, len(hits))
+	startTimes := make([]float64, len(hits))
+	durations := make([]float64, len(hits))
+	tags := make([]json.RawMessage, len(hits))
+	serviceTags := make([]json.RawMessage, len(hits))
+	logs := make([]json.RawMessage, len(hits))
+	stackTraces := make([]*json.RawMessage, len(hits))
+
+	for i, hit := range hits {
+		source, _ := hit["_source"].(map[string]interface{})
+		// attributes can either be nested objects or keys in dot notation
+		span := utils.FlattenNestedObject(source)
+
+		traceIDs[i], _ = span["traceId"].(string)
+		spanIDs[i], _ = span["spanId"].(string)
+		parentSpanIDs[i], _ = span["parentSpanId"].(string)
+		operationNames[i], _ = span["name"].(string)
+		serviceNames[i], _ = span["serviceName"].(string)
+		if startTime := parseTimeValue(span["startTime"]); startTime != nil {
+			startTimes[i] = float64(startTime.UnixNano()) / float64(time.Millisecond)
+		}
+		if durationInNanos, ok := span["durationInNanos"].(float64); ok {
+			durations[i] = durationInNanos / float64(time.Millisecond)
+		}
+
+		events, _ := span["events"].([]interface{})
+		errors := getSpanErrors(events)
+
+		// the trace view needs the error tag to show the error icon next to the span
+		spanTags := append(getAttributes(span, "span.attributes."), traceKeyValuePair{Key: "error", Value: len(errors) > 0})
+		tags[i] = mustMarshalRaw(spanTags)
+		serviceTags[i] = mustMarshalRaw(getAttributes(span, "resource.attributes."))
+		logs[i] = mustMarshalRaw(getSpanLogs(events))
+		if len(errors) > 0 {
+			stackTrace := mustMarshalRaw(errors)
+			stackTraces[i] = &stackTrace
+		}
+	}
+
+	frame := data.NewFrame("Trace",
+		data.NewField("traceID", nil, traceIDs),
+		data.NewField("serviceName", nil, serviceNames),
+		data.NewField("parentSpanID", nil, parentSpanIDs),
+		data.NewField("spanID", nil, spanIDs),
+		data.NewField("operationName", nil, operationNames),
+		data.NewField("startTime", nil, startTimes),
+		data.NewField("duration", nil, durations),
+		data.NewField("tags", nil, tags),
+		data.NewField("serviceTags", nil, serviceTags),
+		data.NewField("stackTraces", nil, stackTraces),
+		data.NewField("logs", nil, logs),
+	)
+	frame.Meta = &data.FrameMeta{
+		PreferredVisualization: data.VisTypeTrace,
+	}
+	return frame
+}
+
+func getAttributes(span map[string]interface{}, prefix string) []traceKeyValuePair {
+	attributes := make([]traceKeyValuePair, 0)
+	for key, value := range span {
+		if strings.HasPrefix(key, prefix) {
+			attributes = append(attributes, traceKeyValuePair{Key: strings.TrimPrefix(key, prefix), Value: value})
+		}
+	}
+	sort.Slice(attributes, func(i, j int) bool {
+		return attributes[i].Key < attributes[j].Key
+	})
+	return attributes
+}
+
+func getSpanErrors(events []interface{}) []string {
+	errors := make([]string, 0)
+	for _, e := range events {
+		event := utils.NewJsonFromAny(e)
+		if err := event.GetPath("attributes", "error").MustString(); err != "" {
+			errors = append(errors, event.Get("name").MustString()+": "+err)
+		}
+	}
+	return errors
+}
+
+func getSpanLogs(events []interface{}) []traceLog {
+	logs := make([]traceLog, 0, len(events))
+	for _, e := range events {
+		event := utils.NewJsonFromAny(e)
+		log := traceLog{
+			Fields: []traceKeyValuePair{{Key: "name", Value: event.Get("name").MustString()}},
+		}
+		if timestamp := parseTimeValue(event.Get("time").Interface()); timestamp != nil {
+			log.Timestamp = float64(timestamp.UnixNano()) / float64(time.Millisecond)
+		}
+		logs = append(logs, log)
+	}
+	return logs
+}
+
+func mustMarshalRaw(v interface{}) json.RawMessage {
+	bytes, err := json.Marshal(v)
+	if err != nil {
+		return json.RawMessage("null")
+	}
+	return bytes
+}
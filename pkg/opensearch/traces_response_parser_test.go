@@ -0,0 +1,136 @@
+package opensearch
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/opensearch-datasource/pkg/opensearch/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_TracesResponseParser(t *testing.T) {
+	t.Run("Trace list", func(t *testing.T) {
+		query := &Query{RefID: "A", RawQuery: "serviceName:frontend", LuceneQueryType: luceneQueryTypeTraces}
+		response := `{
+			"responses": [
+				{
+					"aggregations": {
+						"traces": {
+							"buckets": [
+								{
+									"key": "000000000000000001c871606e1e5e6d",
+									"doc_count": 5,
+									"trace_group": { "buckets": [{ "key": "HTTP GET", "doc_count": 5 }] },
+									"latency": { "value": 12.34 },
+									"error_count": { "doc_count": 1 },
+									"last_updated": { "value": 1526406600000, "value_as_string": "2018-05-15T17:50:00.000Z" }
+								}
+							]
+						}
+					}
+				}
+			]
+		}`
+		rp, err := newTracesResponseParserForTest(response, query)
+		require.NoError(t, err)
+		result := rp.parse()
+
+		queryRes := result.Responses["A"]
+		require.NoError(t, queryRes.Error)
+		require.Len(t, queryRes.Frames, 1)
+		frame := queryRes.Frames[0]
+		assert.Equal(t, data.VisTypeTable, string(frame.Meta.PreferredVisualization))
+		require.Equal(t, 1, frame.Rows())
+		require.Len(t, frame.Fields, 5)
+		assert.Equal(t, "000000000000000001c871606e1e5e6d", frame.Fields[0].At(0))
+		assert.Equal(t, "traceId: ${__value.raw}", frame.Fields[0].Config.Links[0].Internal.Query.(map[string]interface{})["query"])
+		assert.Equal(t, "test-uid", frame.Fields[0].Config.Links[0].Internal.DatasourceUID)
+		assert.Equal(t, "HTTP GET", frame.Fields[1].At(0))
+		assert.Equal(t, 12.34, *frame.Fields[2].At(0).(*float64))
+		assert.Equal(t, float64(1), frame.Fields[3].At(0))
+		assert.Equal(t, time.Date(2018, time.May, 15, 17, 50, 0, 0, time.UTC), *frame.Fields[4].At(0).(*time.Time))
+	})
+
+	t.Run("Trace spans", func(t *testing.T) {
+		query := &Query{RefID: "A", RawQuery: "traceId: 000000000000000001c871606e1e5e6d", LuceneQueryType: luceneQueryTypeTraces}
+		response := `{
+			"responses": [
+				{
+					"hits": {
+						"hits": [
+							{
+								"_source": {
+									"traceId": "000000000000000001c871606e1e5e6d",
+									"spanId": "a1",
+									"parentSpanId": "",
+									"name": "HTTP GET",
+									"serviceName": "frontend",
+									"startTime": "2018-05-15T17:50:00.000Z",
+									"durationInNanos": 2500000,
+									"span.attributes.http@status_code": 500,
+									"resource": { "attributes": { "host": "server-1" } },
+									"events": [
+										{ "name": "exception", "time": "2018-05-15T17:50:00.001Z", "attributes": { "error": "boom" } }
+									]
+								}
+							}
+						]
+					}
+				}
+			]
+		}`
+		rp, err := newTracesResponseParserForTest(response, query)
+		require.NoError(t, err)
+		result := rp.parse()
+
+		queryRes := result.Responses["A"]
+		require.Len(t, queryRes.Frames, 1)
+		frame := queryRes.Frames[0]
+		assert.Equal(t, data.VisTypeTrace, string(frame.Meta.PreferredVisualization))
+		require.Equal(t, 1, frame.Rows())
+
+		values := make(map[string]interface{})
+		for _, f := range frame.Fields {
+			values[f.Name] = f.At(0)
+		}
+		assert.Equal(t, "000000000000000001c871606e1e5e6d", values["traceID"])
+		assert.Equal(t, "a1", values["spanID"])
+		assert.Equal(t, "", values["parentSpanID"])
+		assert.Equal(t, "HTTP GET", values["operationName"])
+		assert.Equal(t, "frontend", values["serviceName"])
+		assert.Equal(t, float64(1526406600000), values["startTime"])
+		assert.Equal(t, 2.5, values["duration"])
+		assert.JSONEq(t, `[{"key":"http@status_code","value":500},{"key":"error","value":true}]`, string(values["tags"].(json.RawMessage)))
+		assert.JSONEq(t, `[{"key":"host","value":"server-1"}]`, string(values["serviceTags"].(json.RawMessage)))
+		assert.JSONEq(t, `["exception: boom"]`, string(*values["stackTraces"].(*json.RawMessage)))
+		assert.JSONEq(t, `[{"timestamp":1526406600001,"fields":[{"key":"name","value":"exception"}]}]`, string(values["logs"].(json.RawMessage)))
+	})
+
+	t.Run("Error response", func(t *testing.T) {
+		query := &Query{RefID: "A", LuceneQueryType: luceneQueryTypeTraces}
+		response := `{
+			"responses": [
+				{ "error": { "reason": "index not found" } }
+			]
+		}`
+		rp, err := newTracesResponseParserForTest(response, query)
+		require.NoError(t, err)
+		result := rp.parse()
+
+		assert.EqualError(t, result.Responses["A"].Error, "index not found")
+	})
+}
+
+func newTracesResponseParserForTest(responseBody string, targets ...*Query) (*tracesResponseParser, error) {
+	var response client.MultiSearchResponse
+	err := json.Unmarshal([]byte(responseBody), &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return newTracesResponseParser(response.Responses, targets, &backend.DataSourceInstanceSettings{UID: "test-uid", Name: "OpenSearch"}), nil
+}
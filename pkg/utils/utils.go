@@ -29,3 +29,26 @@ func NullFloatToNullableTime(ts null.Float) *time.Time {
 	timestamp := time.UnixMilli(int64(ts.Float64)).UTC()
 	return &timestamp
 }
+
+// FlattenNestedObject flattens nested objects into a single level map where
+// the keys of nested values are joined with a dot, e.g. `level1.level2`.
+func FlattenNestedObject(target map[string]interface{}) map[string]interface{} {
+	output := make(map[string]interface{})
+	flattenNestedObject(target, "", output)
+	return output
+}
+
+func flattenNestedObject(target map[string]interface{}, prefix string, output map[string]interface{}) {
+	for key, value := range target {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		if nested, ok := value.(map[string]interface{}); ok && len(nested) > 0 {
+			flattenNestedObject(nested, key, output)
+			continue
+		}
+
+		output[key] = value
+	}
+}
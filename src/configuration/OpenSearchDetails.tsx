@@ -125,6 +125,20 @@ export const OpenSearchDetails = (props: Props) => {
             />
           </div>
         </div>
+        {value.jsonData.interval && (
+          <div className="gf-form max-width-30">
+            <FormField
+              aria-label="Index time zone input"
+              labelWidth={10}
+              inputWidth={15}
+              label="Index time zone"
+              value={value.jsonData.indexTimeZone || ''}
+              onChange={jsonDataChangeHandler('indexTimeZone', value, onChange)}
+              placeholder="UTC"
+              tooltip="The time zone the dates of the index names are in, e.g. Europe/Berlin."
+            />
+          </div>
+        )}
 
         <div className="gf-form max-width-25">
           <FormField
@@ -174,6 +188,17 @@ export const OpenSearchDetails = (props: Props) => {
             />
           </div>
         )}
+        <div className="gf-form max-width-30">
+          <FormField
+            aria-label="Max concurrent queries input"
+            labelWidth={15}
+            label="Max concurrent queries"
+            value={value.jsonData.maxConcurrentQueries || ''}
+            onChange={jsonDataChangeHandler('maxConcurrentQueries', value, onChange)}
+            placeholder="5"
+            tooltip="The number of requests a panel sends to the cluster at the same time."
+          />
+        </div>
         <div className="gf-form-inline">
           <div className="gf-form">
             <FormField
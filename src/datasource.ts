@@ -76,7 +76,7 @@ export class OpenSearchDatasource extends DataSourceApi<OpenSearchQuery, OpenSea
     this.timeField = settingsData.timeField;
     this.flavor = settingsData.flavor || Flavor.OpenSearch;
     this.version = settingsData.version;
-    this.indexPattern = new IndexPattern(this.index, settingsData.interval);
+    this.indexPattern = new IndexPattern(this.index, settingsData.interval, settingsData.indexTimeZone);
     this.interval = settingsData.timeInterval;
     this.maxConcurrentShardRequests = settingsData.maxConcurrentShardRequests;
     this.queryBuilder = new QueryBuilder({
@@ -473,6 +473,10 @@ export class OpenSearchDatasource extends DataSourceApi<OpenSearchQuery, OpenSea
           break;
         case QueryType.Lucene:
         default:
+          // the date histograms are rounded in the time zone of the dashboard unless the query sets one
+          if (!target.timeZone && options.timezone) {
+            target.timeZone = options.timezone;
+          }
           luceneTargets.push(target);
       }
     }
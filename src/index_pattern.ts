@@ -1,4 +1,4 @@
-import { toUtc, dateTime, DateTime } from '@grafana/data';
+import { dateTime, dateTimeForTimeZone, DateTime, DateTimeInput } from '@grafana/data';
 
 const intervalMap: any = {
   Hourly: { startOf: 'hour', amount: 'hours' },
@@ -11,11 +11,16 @@ const intervalMap: any = {
 export class IndexPattern {
   private dateLocale = 'en';
 
-  constructor(private pattern: any, private interval?: string) {}
+  constructor(private pattern: any, private interval?: string, private timeZone?: string) {}
+
+  // the dates of the index names are in the time zone of the index, or in UTC when none is set
+  private inIndexTimeZone(input?: DateTimeInput): DateTime {
+    return dateTimeForTimeZone(this.timeZone || 'utc', input);
+  }
 
   getIndexForToday() {
     if (this.interval) {
-      return toUtc()
+      return this.inIndexTimeZone()
         .locale(this.dateLocale)
         .format(this.pattern);
     } else {
@@ -34,11 +39,10 @@ export class IndexPattern {
     }
 
     const intervalInfo = intervalMap[this.interval];
-    const start = dateTime(from || dateTime(to).add(-indexOffset, intervalInfo.amount))
-      .utc()
-      .startOf(intervalInfo.startOf);
-    const endEpoch = dateTime(to || dateTime(from).add(indexOffset, intervalInfo.amount))
-      .utc()
+    const start = this.inIndexTimeZone(from || dateTime(to).add(-indexOffset, intervalInfo.amount)).startOf(
+      intervalInfo.startOf
+    );
+    const endEpoch = this.inIndexTimeZone(to || dateTime(from).add(indexOffset, intervalInfo.amount))
       .startOf(intervalInfo.startOf)
       .valueOf();
     const indexList = [];
@@ -58,6 +58,16 @@ describe('IndexPattern', () => {
 
         expect(pattern.getIndexList(from, to)).toEqual(expected);
       });
+
+      test('should return the index list in the time zone of the index', () => {
+        const pattern = new IndexPattern('[asd-]YYYY.MM.DD', 'Daily', 'Europe/Berlin');
+        const from = dateTime(1432940523000);
+        const to = dateTime(1433153106000);
+
+        const expected = ['asd-2015.05.30', 'asd-2015.05.31', 'asd-2015.06.01'];
+
+        expect(pattern.getIndexList(from, to)).toEqual(expected);
+      });
     });
   });
 
@@ -17,6 +17,8 @@ export interface OpenSearchOptions extends DataSourceJsonData {
   interval?: string;
   timeInterval: string;
   maxConcurrentShardRequests?: number;
+  maxConcurrentQueries?: number;
+  indexTimeZone?: string;
   logMessageField?: string;
   logLevelField?: string;
   dataLinks?: DataLinkConfig[];
@@ -78,6 +80,8 @@ export interface OpenSearchQuery extends DataQuery {
   timeField?: string;
   queryType?: QueryType;
   format?: PPLFormatType;
+  maxRows?: number;
+  timeZone?: string;
   luceneQueryType?: LuceneQueryType;
 }
 
@@ -101,6 +105,7 @@ export enum Flavor {
 export enum LuceneQueryType {
   Traces = 'Traces',
   Metric = 'Metric',
+  RawDSL = 'RawDSL',
 }
 
 export type AggsForTraces = {